@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// runAtRiskCommand lists the open incidents of the country that breached their SLA or are due within
+// the -horizon, and projects the SLA performance of the current month
+func runAtRiskCommand(incidents sla.Incidents) error {
+	countryConfig, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	if flagVars.horizon < 0 {
+		return &UsageError{Message: fmt.Sprintf("invalid -horizon %s, it cannot be negative", flagVars.horizon)}
+	}
+	// the incidents are only at risk now, so any other month is stale
+	now := time.Now()
+	month, year := int(now.Month()), now.Year()
+	if monthOnCommandLine && (flagVars.month != month || flagVars.year != year) {
+		return &UsageError{Message: fmt.Sprintf("atrisk only runs on the current month %s %d, leave out -month and -year",
+			sla.MonthNames[month], year)}
+	}
+	incidents, _, err = prepareIncidents(incidents, countryConfig, month, year)
+	if err != nil {
+		return err
+	}
+	forecast := sla.ForecastSLA(incidents, month, year, now, flagVars.horizon)
+	return writeAtRisk(os.Stdout, countryConfig.Name, forecast, outputFormat("table"))
+}
+
+// writeAtRisk writes the forecast in the format: table or json
+func writeAtRisk(out io.Writer, country string, forecast sla.Forecast, format string) error {
+	switch format {
+	case "table":
+		return writeAtRiskTable(out, country, forecast)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if forecast.AtRisk == nil {
+			forecast.AtRisk = []sla.AtRiskIncident{}
+		}
+		return encoder.Encode(forecast)
+	}
+	return &UsageError{Message: fmt.Sprintf("invalid -format %s for atrisk, use table or json", format)}
+}
+
+// writeAtRiskTable aligns the incidents at risk, the most urgent first, followed by the projection per priority
+func writeAtRiskTable(out io.Writer, country string, forecast sla.Forecast) error {
+	const timeFormat = "2006-01-02 15:04"
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "Open incidents of %s breached or due within %s at %s\n\n", country,
+		formatRemaining(forecast.Horizon), forecast.At.Format(timeFormat))
+	if len(forecast.AtRisk) == 0 {
+		fmt.Fprintf(writer, "None\n")
+	} else {
+		fmt.Fprintf(writer, "ID\tPriority\tCreated\tDue\tRemaining\tService\t\n")
+	}
+	for _, incident := range forecast.AtRisk {
+		remaining := formatRemaining(incident.Remaining)
+		if incident.Remaining < 0 {
+			remaining = "breached " + formatRemaining(-incident.Remaining) + " ago"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t\n", incident.ID, incident.Priority,
+			incident.CreatedAt.Format(timeFormat), incident.Due.Format(timeFormat), remaining, incident.Service)
+	}
+
+	percentage := func(value *float64) string {
+		if value == nil {
+			return "-"
+		}
+		return formatPercentage(*value, 1)
+	}
+	fmt.Fprintf(writer, "\nPriority\tTarget\tSolved\tSLA Met\tOpen\tBreached\tCurrent\tBest\tWorst\t\n")
+	for _, priorityData := range forecast.Priorities {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t\n", priorityData.Priority,
+			formatPercentage(priorityData.Target, 0), priorityData.Total, priorityData.SLAMet, priorityData.Open,
+			priorityData.Breached, percentage(priorityData.Current), percentage(priorityData.Best),
+			percentage(priorityData.Worst))
+	}
+	return writer.Flush()
+}
+
+// formatRemaining rounds the duration to minutes and leaves out the zero minutes and seconds, e.g. 3h20m or 24h
+func formatRemaining(duration time.Duration) string {
+	text := duration.Round(time.Minute).String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
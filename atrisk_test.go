@@ -0,0 +1,21 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_runAtRiskCommand(t *testing.T) {
+	config = Config{Countries: []Country{{Name: "Sweden", SLAs: []sla.SLA{{Priority: "Critical", Hours: 4}}}}}
+	flagVars.country, flagVars.horizon = "Sweden", 0
+	defer func() { monthOnCommandLine = false }()
+
+	// another month than the current one is not at risk anymore
+	flagVars.month, flagVars.year, monthOnCommandLine = 10, 2019, true
+	var usageErr *UsageError
+	if err := runAtRiskCommand(nil); !errors.As(err, &usageErr) {
+		t.Errorf("Expected a usage error for October 2019, got %v", err)
+	}
+}
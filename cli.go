@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// command is a subcommand of goreport with the nouns and flags it accepts
+type command struct {
+	name     string
+	nouns    []string // the command requires one of these nouns, none if empty
+	args     string   // usage of the arguments following the command and noun, empty if none
+	summary  string
+	flags    []string          // flags accepted besides the global flags
+	usages   map[string]string // usage of the flags that mean something else for this command
+	examples []string
+	noConfig bool // the command runs without the configuration file and incidents
+	noInput  bool // the command runs without the incidents
+	run      func(incidents sla.Incidents) error
+}
+
+// globalFlags are accepted by every command, before or after it
+var globalFlags = []string{"cfg", "input", "country", "month", "year", "now", "v"}
+
+// adjustmentFlags change which incidents are counted and how, for the commands that compute the report
+var adjustmentFlags = []string{"where", "reference", "corrections", "nofilter", "reverse"}
+
+// commands are the subcommands in the order of the usage
+var commands []*command
+
+// commandArgs are the noun and arguments following the command
+var commandArgs []string
+
+func init() {
+	reportFlags := append([]string{"output", "format"}, adjustmentFlags...)
+	reportUsages := map[string]string{"format": "Output format(s) of the report, comma separated (xlsx, html, pdf, json, csv), defaults to xlsx"}
+	commands = []*command{
+		{name: "report", summary: "Write the report of the country and month", flags: reportFlags, usages: reportUsages,
+			examples: []string{"goreport -country Sweden report", "goreport report -month 9 -year 2019 -format xlsx,pdf"},
+			run:      runReportCommand},
+		{name: "summary", summary: "Print a Markdown digest of the report month", flags: adjustmentFlags,
+			examples: []string{"goreport -country Sweden summary | mail"},
+			run:      runSummaryCommand},
+		{name: "mail", summary: "Write the report and mail it to the recipients of the country",
+			flags: append([]string{"dry-run"}, reportFlags...), usages: reportUsages,
+			examples: []string{"goreport -country Sweden mail -format xlsx,pdf", "goreport mail -dry-run"},
+			run:      runMailCommand},
+		{name: "review", summary: "Decide on the Critical and breached incidents of the month, one by one",
+			flags:    append([]string{"reviewer"}, adjustmentFlags...),
+			examples: []string{"goreport -country Sweden review -reviewer jdoe"},
+			run:      runReviewCommand},
+		{name: "import", nouns: []string{"corrections"}, summary: "Import the adjustments of reference workbooks into the corrections file",
+			flags:    []string{"reference", "corrections"},
+			examples: []string{"goreport import corrections -reference prev"},
+			noInput:  true, run: func(sla.Incidents) error { return runImportCommand() }},
+		{name: "export", nouns: []string{"prometheus"}, summary: "Export the numbers of all countries for the current month as Prometheus metrics",
+			flags:    append([]string{"output"}, adjustmentFlags...),
+			examples: []string{"goreport export prometheus -output /var/lib/node_exporter/goreport.prom", "goreport export prometheus -month 9 -year 2019"},
+			run:      runExportCommand},
+		{name: "serve", summary: "Serve the API, dashboard and metrics over HTTP",
+			flags:    append([]string{"listen"}, adjustmentFlags...),
+			examples: []string{"goreport serve -listen :9090"},
+			run:      func(incidents sla.Incidents) error { return runServer(incidents, flagVars.listenAddress) }},
+		{name: "gui", summary: "Open the terminal UI to adjust incidents and generate the report",
+			flags: append([]string{"reviewer"}, reportFlags...), usages: reportUsages,
+			examples: []string{"goreport -country Sweden gui"},
+			run:      RunGui},
+		{name: "list", nouns: listNounNames,
+			summary: "List the countries, product categories, services, CIs, business areas or priorities with their number of incidents",
+			flags:   []string{"where", "sort", "per", "format"},
+			usages:  map[string]string{"format": "Output format of the list (table, csv, json), defaults to table"},
+			examples: []string{
+				"goreport list countries",
+				"goreport -country Sweden list services -where 'priority == Critical'",
+				"goreport list cis -sort count -per month -format csv",
+			},
+			run: runListCommand},
+		{name: "show", nouns: []string{"incident"}, args: "<id>",
+			summary:  "Show the imported fields, adjustments, SLA computation and outage minutes of an incident",
+			flags:    []string{"reference", "corrections", "nofilter", "reverse"},
+			examples: []string{"goreport show incident INC000123", "goreport show incident INC000123 -reference same"},
+			run:      runShowCommand},
+		{name: "atrisk", summary: "List the open incidents that breached or are due soon and project the SLA performance of the month",
+			flags:    append([]string{"horizon", "format"}, adjustmentFlags...),
+			usages:   map[string]string{"format": "Output format of the incidents at risk (table, json), defaults to table"},
+			examples: []string{"goreport -country Sweden atrisk", "goreport atrisk -horizon 4h -format json"},
+			run:      runAtRiskCommand},
+		{name: "validate", summary: "Check the configuration, input and adjustments of all countries without writing anything",
+			flags:    adjustmentFlags,
+			examples: []string{"goreport validate -reference same"},
+			run:      runValidateCommand},
+		{name: "help", args: "[<command>]", summary: "Show the usage of goreport or of a command",
+			examples: []string{"goreport help report"},
+			noConfig: true, run: func(sla.Incidents) error { return runHelpCommand(os.Stdout) }},
+		{name: "completion", nouns: completionShells, summary: "Print the shell completion script",
+			examples: []string{"source <(goreport completion bash)", "goreport completion fish > ~/.config/fish/completions/goreport.fish"},
+			noConfig: true, run: func(sla.Incidents) error { return runCompletionCommand(os.Stdout) }},
+	}
+}
+
+// findCommand returns the command with the name, nil if there is none
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// parseCommandLine parses the global flags, the command with its noun and the flags of the command.
+// Flags can be given before and after the command, but only the global flags and the ones of the command.
+// A -h or -help after the command is the help of the command.
+func parseCommandLine(flags *flag.FlagSet, args []string) (*command, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return nil, &UsageError{Message: "no command specified, see goreport help"}
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return nil, &UsageError{Message: fmt.Sprintf("unknown command %s, see goreport help", args[0])}
+	}
+
+	// the flags given before the command must be accepted by it too
+	var rejected []string
+	flags.Visit(func(f *flag.Flag) {
+		if !cmd.accepts(f.Name) {
+			rejected = append(rejected, "-"+f.Name)
+		}
+	})
+	if len(rejected) > 0 {
+		return nil, &UsageError{Message: fmt.Sprintf("%s is not used by %s, see goreport help %s",
+			strings.Join(rejected, ", "), cmd.name, cmd.name)}
+	}
+
+	// the flags of the command share their values with the global set, so both can be used
+	commandFlags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	commandFlags.SetOutput(ioutil.Discard)
+	for _, name := range cmd.flagNames() {
+		f := flags.Lookup(name)
+		commandFlags.Var(f.Value, f.Name, f.Usage)
+	}
+
+	// nouns and arguments can be mixed with the flags
+	args = args[1:]
+	var positional []string
+	for {
+		err := commandFlags.Parse(args)
+		if errors.Is(err, flag.ErrHelp) {
+			commandArgs = []string{cmd.name}
+			return findCommand("help"), nil
+		}
+		if err != nil {
+			return nil, &UsageError{Message: fmt.Sprintf("%s: %v, see goreport help %s", cmd.name, err, cmd.name)}
+		}
+		args = commandFlags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if err := cmd.checkArgs(positional); err != nil {
+		return nil, err
+	}
+	commandArgs = positional
+	return cmd, nil
+}
+
+// checkArgs checks the noun and the number of arguments following it
+func (cmd *command) checkArgs(args []string) error {
+	expected := 0
+	if len(cmd.nouns) > 0 {
+		if len(args) == 0 {
+			return &UsageError{Message: fmt.Sprintf("%s needs one of %s", cmd.name, strings.Join(cmd.nouns, ", "))}
+		}
+		if !containsString(cmd.nouns, args[0]) {
+			return &UsageError{Message: fmt.Sprintf("unknown %s %s, use one of %s", cmd.name, args[0], strings.Join(cmd.nouns, ", "))}
+		}
+		expected++
+	}
+	// arguments in brackets are optional
+	for _, arg := range strings.Fields(cmd.args) {
+		if !strings.HasPrefix(arg, "[") && len(args) <= expected {
+			return &UsageError{Message: fmt.Sprintf("%s needs %s", cmd.name, cmd.args)}
+		}
+		expected++
+	}
+	if len(args) > expected {
+		return &UsageError{Message: fmt.Sprintf("unexpected argument %s for %s", args[expected], cmd.name)}
+	}
+	return nil
+}
+
+// flagNames returns the global flags followed by the flags of the command
+func (cmd *command) flagNames() []string {
+	return append(append([]string{}, globalFlags...), cmd.flags...)
+}
+
+func (cmd *command) accepts(flagName string) bool {
+	return containsString(globalFlags, flagName) || containsString(cmd.flags, flagName)
+}
+
+// usage returns the synopsis of the command
+func (cmd *command) usage() string {
+	usage := "goreport [flags] " + cmd.name
+	if len(cmd.nouns) > 0 {
+		usage += " " + strings.Join(cmd.nouns, "|")
+	}
+	if cmd.args != "" {
+		usage += " " + cmd.args
+	}
+	if len(cmd.flags) > 0 {
+		usage += " [flags]"
+	}
+	return usage
+}
+
+// runHelpCommand shows the usage of goreport, or of the command following help
+func runHelpCommand(out io.Writer) error {
+	if len(commandArgs) == 0 {
+		printUsage(out)
+		return nil
+	}
+	cmd := findCommand(commandArgs[0])
+	if cmd == nil {
+		return &UsageError{Message: fmt.Sprintf("unknown command %s, see goreport help", commandArgs[0])}
+	}
+	printCommandUsage(out, cmd)
+	return nil
+}
+
+// printUsage shows the commands and the global flags
+func printUsage(out io.Writer) {
+	fmt.Fprintf(out, "Usage: goreport [flags] <command> [<noun>] [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nGlobal flags:\n")
+	printFlags(out, globalFlags, nil)
+	fmt.Fprintf(out, "\nRun 'goreport help <command>' for the flags and examples of a command.\n")
+}
+
+// printCommandUsage shows the synopsis, flags and examples of a command
+func printCommandUsage(out io.Writer, cmd *command) {
+	fmt.Fprintf(out, "Usage: %s\n\n%s\n", cmd.usage(), cmd.summary)
+	if len(cmd.flags) > 0 {
+		fmt.Fprintf(out, "\nFlags:\n")
+		printFlags(out, cmd.flags, cmd.usages)
+	}
+	if len(cmd.examples) > 0 {
+		fmt.Fprintf(out, "\nExamples:\n")
+		for _, example := range cmd.examples {
+			fmt.Fprintf(out, "  %s\n", example)
+		}
+	}
+	fmt.Fprintf(out, "\nThe global flags are accepted too, see goreport help.\n")
+}
+
+// printFlags prints the flags with their usage and default value, in the order given.
+// usages replaces the usage of the flags that mean something else for the command.
+func printFlags(out io.Writer, names []string, usages map[string]string) {
+	for _, name := range names {
+		f := flag.CommandLine.Lookup(name)
+		valueName, usage := flag.UnquoteUsage(f)
+		if commandUsage, found := usages[name]; found {
+			usage = commandUsage
+		}
+		synopsis := "-" + f.Name
+		if valueName != "" {
+			synopsis += " " + valueName
+		}
+		fmt.Fprintf(out, "  %s\n    \t%s", synopsis, usage)
+		if valueName == "string" && f.DefValue != "" {
+			fmt.Fprintf(out, " (default %q)", f.DefValue)
+		} else if valueName == "duration" {
+			fmt.Fprintf(out, " (default %s)", f.DefValue)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// isBoolFlag returns whether the flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
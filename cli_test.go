@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseCommandLine(t *testing.T) {
+	newFlags := func() *flag.FlagSet {
+		flags := flag.NewFlagSet("goreport", flag.ContinueOnError)
+		defineFlags(flags)
+		return flags
+	}
+
+	cmd, err := parseCommandLine(newFlags(), []string{"-country", "Sweden", "list", "services", "-where", "priority == High"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.name != "list" || !reflect.DeepEqual(commandArgs, []string{"services"}) {
+		t.Errorf("Expected list services, got %s %v", cmd.name, commandArgs)
+	}
+	if flagVars.country != "Sweden" || flagVars.where != "priority == High" {
+		t.Errorf("Expected the flags before and after the command, got %s and %s", flagVars.country, flagVars.where)
+	}
+
+	// the flags can also follow the noun and be mixed with it
+	cmd, err = parseCommandLine(newFlags(), []string{"import", "-reference", "prev", "corrections"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.name != "import" || flagVars.referenceFilename != "prev" || !hasNoun("corrections") {
+		t.Errorf("Expected import corrections with -reference prev, got %s %v %s", cmd.name, commandArgs, flagVars.referenceFilename)
+	}
+
+	cmd, err = parseCommandLine(newFlags(), []string{"report", "-h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.name != "help" || !reflect.DeepEqual(commandArgs, []string{"report"}) {
+		t.Errorf("Expected the help of report, got %s %v", cmd.name, commandArgs)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"bogus"},
+		{"list"},
+		{"list", "foo"},
+		{"list", "countries", "extra"},
+		{"-reverse", "list", "countries"},
+		{"list", "countries", "-listen", ":9090"},
+		{"help", "report", "extra"},
+	} {
+		_, err := parseCommandLine(newFlags(), args)
+		var usageErr *UsageError
+		if !errors.As(err, &usageErr) {
+			t.Errorf("Expected a usage error for %v, got %v", args, err)
+		}
+	}
+}
+
+func Test_runHelpCommand(t *testing.T) {
+	var out bytes.Buffer
+	commandArgs = []string{"mail"}
+	if err := runHelpCommand(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"goreport [flags] mail [flags]", "-dry-run", "Examples:"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the help of mail, got\n%s", expected, out.String())
+		}
+	}
+
+	// -format only lists the formats of the command
+	for name, expected := range map[string]string{"report": "xlsx, html", "list": "table, csv, json", "atrisk": "table, json"} {
+		out.Reset()
+		commandArgs = []string{name}
+		if err := runHelpCommand(&out); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), expected) || strings.Count(out.String(), "defaults to") != 1 {
+			t.Errorf("Expected only the formats %s in the help of %s, got\n%s", expected, name, out.String())
+		}
+	}
+
+	commandArgs = []string{"bogus"}
+	if err := runHelpCommand(&out); err == nil {
+		t.Errorf("Expected an error for the help of an unknown command")
+	}
+}
+
+func Test_runCompletionCommand(t *testing.T) {
+	for _, shell := range completionShells {
+		var out bytes.Buffer
+		commandArgs = []string{shell}
+		if err := runCompletionCommand(&out); err != nil {
+			t.Fatal(err)
+		}
+		for _, cmd := range commands {
+			if !strings.Contains(out.String(), cmd.name) {
+				t.Errorf("Expected command %s in the %s completion", cmd.name, shell)
+			}
+		}
+	}
+}
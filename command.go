@@ -2,54 +2,96 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/ronaldlens/goreport/sla"
 )
 
 // command line arguments
 var flagVars struct {
-	configFilename    string
-	inputFilename     string
-	referenceFilename string
-	outputFilename    string
-	country           string
-	month             int
-	year              int
-	now               bool
-	verbose           bool
-	reverse           bool
-	nofilter          bool
+	configFilename      string
+	inputFilename       string
+	referenceFilename   string
+	correctionsFilename string
+	reviewer            string
+	where               string
+	outputFilename      string
+	format              string
+	sortBy              string
+	per                 string
+	listenAddress       string
+	horizon             time.Duration
+	country             string
+	month               int
+	year                int
+	now                 bool
+	dryRun              bool
+	verbose             bool
+	reverse             bool
+	nofilter            bool
 }
 
 var config Config
 
+// whereFilter is the parsed -where expression, nil if none was given
+var whereFilter sla.Expression
+
+// monthOnCommandLine is set when both -month and -year were given
+var monthOnCommandLine bool
+
 func init() {
-	// set up all command line flags
-	flag.StringVar(&flagVars.configFilename, "cfg", "goreport.yaml", "Configuration filename")
-	flag.StringVar(&flagVars.inputFilename, "input", "allincidents.csv", "Tab delimited incident input filename")
-	flag.StringVar(&flagVars.referenceFilename, "reference", "", "Excel file to use as input reference")
-	flag.StringVar(&flagVars.outputFilename, "output", "", "Output filename to use for xlsx file")
-	flag.StringVar(&flagVars.country, "country", "", "Country to report on")
+	defineFlags(flag.CommandLine)
+	flag.Usage = func() {
+		printUsage(flag.CommandLine.Output())
+	}
+}
 
-	flag.IntVar(&flagVars.month, "month", -1, "Month to report on (1..12)")
-	flag.IntVar(&flagVars.year, "year", -1, "Year to report on")
-	flag.BoolVar(&flagVars.now, "now", false, "Use current month instead of last month")
+// defineFlags sets up all command line flags, the commands accept a subset of them
+func defineFlags(flags *flag.FlagSet) {
+	flags.StringVar(&flagVars.configFilename, "cfg", "goreport.yaml", "Configuration filename")
+	flags.StringVar(&flagVars.inputFilename, "input", "allincidents.csv", "Tab delimited incident input filename")
+	flags.StringVar(&flagVars.referenceFilename, "reference", "", "Excel file(s) to use as input reference, comma separated")
+	flags.StringVar(&flagVars.correctionsFilename, "corrections", "corrections.yaml", "Corrections file (.yaml or .csv) written by review and used by the reports")
+	flags.StringVar(&flagVars.reviewer, "reviewer", "", "Name recorded with the decisions made in review and gui, defaults to the user name")
+	flags.StringVar(&flagVars.outputFilename, "output", "", "Output filename to use for xlsx file")
+	flags.StringVar(&flagVars.format, "format", "", "Output format, see the help of the command")
+	flags.StringVar(&flagVars.sortBy, "sort", "name", "Sort the list by name or count")
+	flags.StringVar(&flagVars.per, "per", "", "Count the incidents of the list per priority or month")
+	flags.StringVar(&flagVars.country, "country", "", "Country to report on")
+	flags.StringVar(&flagVars.where, "where", "", "Only use the incidents matching the expression, e.g. 'priority in (Critical,High) and not slamet'")
 
-	flag.BoolVar(&flagVars.verbose, "v", false, "Increased verbosity")
-	flag.BoolVar(&flagVars.reverse, "reverse", false, "Apply the filters for incidents in reverse")
-	flag.BoolVar(&flagVars.nofilter, "nofilter", false, "Do not apply any filter to incidents")
+	flags.IntVar(&flagVars.month, "month", -1, "Month to report on (1..12), defaults to last month")
+	flags.IntVar(&flagVars.year, "year", -1, "Year to report on, defaults to the year of last month")
+	flags.BoolVar(&flagVars.now, "now", false, "Use current month instead of last month")
+	flags.StringVar(&flagVars.listenAddress, "listen", ":8080", "Address the serve command listens on")
+	flags.DurationVar(&flagVars.horizon, "horizon", 24*time.Hour, "List the open incidents due within this time, e.g. 4h or 72h")
+	flags.BoolVar(&flagVars.dryRun, "dry-run", false, "Write the mail to an .eml file instead of sending it")
 
+	flags.BoolVar(&flagVars.verbose, "v", false, "Increased verbosity")
+	flags.BoolVar(&flagVars.reverse, "reverse", false, "Apply the filters for incidents in reverse")
+	flags.BoolVar(&flagVars.nofilter, "nofilter", false, "Do not apply any filter to incidents")
 }
 
-func processCommandLineArgs() {
-	flag.Parse()
-
+// processCommandLineArgs reads the configuration file and completes the command line with its defaults
+func processCommandLineArgs() error {
 	// read configuration file
 	var err error
 	config, err = readConfig(flagVars.configFilename)
 	if err != nil {
-		log.Fatalf("Error reading config file: %v", err)
+		return &ConfigError{Filename: flagVars.configFilename, Err: err}
+	}
+
+	if flagVars.where != "" {
+		whereFilter, err = sla.ParseWhere(flagVars.where)
+		if err != nil {
+			return fmt.Errorf("invalid -where expression: %w", err)
+		}
 	}
 
 	// get country from config file
@@ -60,110 +102,342 @@ func processCommandLineArgs() {
 
 	// if no month or year was supplied
 	// use last month unless now was supplied as an option
-	if flagVars.month == -1 || flagVars.year == -1 {
+	monthOnCommandLine = flagVars.month != -1 && flagVars.year != -1
+	if !monthOnCommandLine {
 		flagVars.month = int(time.Now().Month())
 		flagVars.year = time.Now().Year()
 		if !flagVars.now {
-			flagVars.month, flagVars.year = getPreviousMonth(flagVars.month, flagVars.year)
+			flagVars.month, flagVars.year = sla.PreviousMonth(flagVars.month, flagVars.year)
 		}
 	}
+	return nil
 }
 
-func processCommandLineCommand(incidents Incidents) {
-	// work through commands
-	if hasCommand("list") {
-		runListCommand(incidents)
-	} else if hasCommand("report") {
-		runReportCommand(incidents)
-	} else if hasCommand("gui") {
-		RunGui(incidents)
-	} else {
-		log.Fatalf("No command specified")
+func runListCommand(incidents sla.Incidents) error {
+	if whereFilter != nil {
+		incidents = incidents.FilterWhere(whereFilter)
+		if flagVars.verbose {
+			log.Printf("%d incidents match %s", len(incidents), flagVars.where)
+		}
 	}
-}
 
-func runListCommand(incidents Incidents) {
-	if hasNoun("countries") {
+	// the countries are listed over all countries
+	if !hasNoun("countries") && flagVars.country != "" {
 		if flagVars.verbose {
-			log.Printf("Listing all countries")
+			log.Printf("Filtering by country %s", flagVars.country)
 		}
-		listCountries(incidents)
-	} else if hasNoun("prodcategories") {
-		if flagVars.country != "" {
-			if flagVars.verbose {
-				log.Printf("Filtering by country %s", flagVars.country)
-			}
-			incidents = incidents.filterByCountry(flagVars.country)
+		incidents = incidents.FilterByCountry(flagVars.country)
+	}
+
+	table, err := buildList(incidents, listNouns[commandArgs[0]], flagVars.per, flagVars.sortBy)
+	if err != nil {
+		return err
+	}
+	return writeList(os.Stdout, table, outputFormat("table"))
+}
+
+func runReportCommand(incidents sla.Incidents) error {
+	countryConfig, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	data, err := prepareReportData(incidents, countryConfig, flagVars.month, flagVars.year)
+	if err != nil {
+		return err
+	}
+	_, err = runReport(&data, flagVars.outputFilename, config.OutputDirectory, outputFormat("xlsx"), flagVars.verbose)
+	return err
+}
+
+func runSummaryCommand(incidents sla.Incidents) error {
+	countryConfig, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	data, err := prepareReportData(incidents, countryConfig, flagVars.month, flagVars.year)
+	if err != nil {
+		return err
+	}
+	writeSummary(os.Stdout, &data)
+	return nil
+}
+
+// runReviewCommand asks for a decision on the Critical and breached incidents of the month
+// and stores them in the corrections file
+func runReviewCommand(incidents sla.Incidents) error {
+	countryConfig, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	incidents, _, err = prepareIncidents(incidents, countryConfig, flagVars.month, flagVars.year)
+	if err != nil {
+		return err
+	}
+	corrections, err := readCorrections(flagVars.correctionsFilename)
+	if err != nil {
+		return &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+	}
+
+	candidates := reviewCandidates(incidents, corrections, flagVars.month, flagVars.year)
+	if len(candidates) == 0 {
+		log.Printf("No incidents to review in %s %d", sla.MonthNames[flagVars.month], flagVars.year)
+		return nil
+	}
+	err = reviewIncidents(os.Stdin, os.Stdout, candidates, &corrections, reviewerName(), func(corrections Corrections) error {
+		return writeCorrections(flagVars.correctionsFilename, corrections)
+	})
+	if err != nil {
+		return &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+	}
+	return nil
+}
+
+// reviewerName returns the name recorded with the decisions: the -reviewer, or the user name
+func reviewerName() string {
+	if flagVars.reviewer != "" {
+		return flagVars.reviewer
+	}
+	return os.Getenv("USER")
+}
+
+// runImportCommand adds the corrected times and exclusions of the reference workbook to the corrections file,
+// to move from editing the workbook to the corrections file
+func runImportCommand() error {
+	if flagVars.referenceFilename == "" {
+		return &UsageError{Message: "no workbook to import from, use -reference"}
+	}
+	corrections, err := readCorrections(flagVars.correctionsFilename)
+	if err != nil {
+		return &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+	}
+	referenceFilenames := getReferenceFilenames(flagVars.referenceFilename, flagVars.country, flagVars.month, flagVars.year)
+	referenceCorrections, _, err := readReferenceFiles(referenceFilenames, config.ReferencePrecedence)
+	if err != nil {
+		return err
+	}
+	added := importReferenceCorrections(&corrections, referenceCorrections, time.Now())
+	err = writeCorrections(flagVars.correctionsFilename, corrections)
+	if err != nil {
+		return &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+	}
+	log.Printf("Imported %d corrections from %s into %s", added, flagVars.referenceFilename, flagVars.correctionsFilename)
+	return nil
+}
+
+// runValidateCommand prepares the report month of every configured country without writing anything,
+// so the configuration, input, reference workbooks and corrections file can be checked before a batch run
+func runValidateCommand(incidents sla.Incidents) error {
+	for _, countryConfig := range config.Countries {
+		countryIncidents, _, err := prepareIncidents(incidents, countryConfig, flagVars.month, flagVars.year)
+		if err != nil {
+			return fmt.Errorf("country %s: %w", countryConfig.Name, err)
 		}
-		listProductCategories(incidents)
-	} else if hasNoun("services") {
-		if flagVars.country != "" {
-			if flagVars.verbose {
-				log.Printf("Filtering by country %s", flagVars.country)
+		countryIncidents = countryIncidents.FilterByMonthYear(flagVars.month, flagVars.year)
+		excluded := 0
+		for _, incident := range countryIncidents {
+			if incident.Exclude {
+				excluded++
 			}
-			incidents = incidents.filterByCountry(flagVars.country)
 		}
-		listServices(incidents)
+		fmt.Printf("%s: %d incidents in %s %d, %d excluded\n", countryConfig.Name, len(countryIncidents),
+			sla.MonthNames[flagVars.month], flagVars.year, excluded)
+	}
+	fmt.Printf("%s and %s are valid\n", flagVars.configFilename, flagVars.inputFilename)
+	return nil
+}
+
+// runExportCommand writes the numbers of all configured countries in a format for monitoring systems
+func runExportCommand(incidents sla.Incidents) error {
+	filename := flagVars.outputFilename
+	if filename == "" {
+		filename = "goreport.prom"
+		if config.OutputDirectory != "" {
+			filename = filepath.Join(config.OutputDirectory, filename)
+		}
+	}
+	month, year := metricsMonth()
+	reports, err := prepareAllReportData(incidents, month, year)
+	if err != nil {
+		return err
+	}
+	err = writeMetricsFile(filename, collectMetrics(reports))
+	if err != nil {
+		return &OutputError{Filename: filename, Err: err}
+	}
+	if flagVars.verbose {
+		log.Printf("Wrote metrics of %d countries to %s", len(reports), filename)
+	}
+	return nil
+}
+
+// runMailCommand generates the report and mails it to the recipients of the country
+// with dry-run, the mail is written to an .eml file next to the report instead
+func runMailCommand(incidents sla.Incidents) error {
+	countryConfig, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	if len(countryConfig.Recipients) == 0 {
+		return &ConfigError{Filename: flagVars.configFilename,
+			Err: fmt.Errorf("no recipients configured for country %s", flagVars.country)}
+	}
+	data, err := prepareReportData(incidents, countryConfig, flagVars.month, flagVars.year)
+	if err != nil {
+		return err
+	}
+	filenames, err := runReport(&data, flagVars.outputFilename, config.OutputDirectory, outputFormat("xlsx"), flagVars.verbose)
+	if err != nil {
+		return err
+	}
+
+	message, err := buildMail(config.SMTP, countryConfig.Recipients, &data, filenames)
+	if err != nil {
+		return fmt.Errorf("creating mail: %w", err)
+	}
+
+	if flagVars.dryRun {
+		filename := getFilenameWithExtension(data.Country, data.Month, data.Year, "eml")
+		if config.OutputDirectory != "" {
+			filename = filepath.Join(config.OutputDirectory, filename)
+		}
+		err = ioutil.WriteFile(filename, message, 0644)
+		if err != nil {
+			return &OutputError{Filename: filename, Err: err}
+		}
+		if flagVars.verbose {
+			log.Printf("Wrote mail to %s", filename)
+		}
+		return nil
+	}
+
+	err = sendMail(config.SMTP, countryConfig.Recipients, message)
+	if err != nil {
+		return &OutputError{Filename: "mail to " + strings.Join(countryConfig.Recipients, ", "), Err: err}
+	}
+	if flagVars.verbose {
+		log.Printf("Mailed report to %s", strings.Join(countryConfig.Recipients, ", "))
+	}
+	return nil
+}
+
+// prepareReportData prepares the incidents of the country and computes the numbers of the report
+func prepareReportData(incidents sla.Incidents, countryConfig Country, month int, year int) (sla.ReportData, error) {
+	return sla.NewReportData(incidents, reportOptions(countryConfig, month, year))
+}
+
+// prepareIncidents reduces the incidents to the ones of the country, processes the reference file
+// and checks the incidents against the SLA of the country.
+// It returns the corporate incidents and the local incidents
+func prepareIncidents(incidents sla.Incidents, countryConfig Country, month int, year int) (sla.Incidents, sla.Incidents, error) {
+	return sla.PrepareIncidents(incidents, reportOptions(countryConfig, month, year))
+}
+
+// reportOptions returns the options to prepare the report of a country, from the configuration file
+// and the command line
+func reportOptions(countryConfig Country, month int, year int) sla.Options {
+	options := sla.Options{
+		Country:          countryConfig.Name,
+		Month:            month,
+		Year:             year,
+		SLAs:             countryConfig.SLAs,
+		MinimumIncidents: countryConfig.MinimumIncidents,
+		SplitArea:        countryConfig.SplitArea,
+		Rules:            config.Rules,
+		CategoryFilters:  countryConfig.CategoryFilters,
+		Filter:           sla.FilterOptions{NoFilter: flagVars.nofilter, Reverse: flagVars.reverse},
+		Where:            whereFilter,
+		Adjust: func(incidents sla.Incidents) (sla.Incidents, []string, error) {
+			return adjustIncidents(incidents, countryConfig.Name, month, year)
+		},
+	}
+	if flagVars.verbose {
+		options.Logf = log.Printf
 	}
+	return options
 }
 
-func runReportCommand(incidents Incidents) {
-	// reduce incidents
-	countryConfig := getCountryFromConfig(config, flagVars.country)
-	incidents = incidents.filterByCountry(flagVars.country)
-	//incidents = incidents.filterOutProdCategories2(countryConfig.FilterOutCategories)
-	localIncidents := incidents.filterCorpLocal(false)
-	incidents = incidents.filterCorpLocal(true)
+// adjustIncidents applies the reference workbooks and the corrections file to the incidents
+// and checks that every adjustment in the 6 months of the report has a reason.
+// It returns the conflicts between the reference workbooks as warnings for the report.
+func adjustIncidents(incidents sla.Incidents, country string, month int, year int) (sla.Incidents, []string, error) {
+	var warnings []string
 
 	// if we are to use a reference xlsx, process it
-	// if the name equals to 'same' use the same name as the output
-	// if the name equals to 'previous' or 'prev' use the xlsx from last month
 	if flagVars.referenceFilename != "" {
-		if flagVars.referenceFilename == "same" {
-			flagVars.referenceFilename = getFilename(flagVars.country, flagVars.month, flagVars.year)
-		} else if flagVars.referenceFilename == "previous" || flagVars.referenceFilename == "prev" {
-			prevMonth, prevYear := getPreviousMonth(flagVars.month, flagVars.year)
-			flagVars.referenceFilename = getFilename(flagVars.country, prevMonth, prevYear)
+		referenceFilenames := getReferenceFilenames(flagVars.referenceFilename, country, month, year)
+		var conflicts []referenceConflict
+		var err error
+		incidents, conflicts, err = ProcessReferenceFile(incidents, referenceFilenames, config.ReferencePrecedence)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, conflict := range conflicts {
+			warnings = append(warnings, "Reference workbooks disagree on "+conflict.describe())
 		}
-		incidents = ProcessReferenceFile(incidents, flagVars.referenceFilename)
 	}
 
-	slaSet := ParseSLAConfig(countryConfig.SLAs)
-	incidents = checkIncidentsAgainstSLA(incidents, slaSet)
-	runReport(&incidents, &localIncidents, flagVars.country, flagVars.month, flagVars.year, countryConfig.SplitArea,
-		flagVars.outputFilename, countryConfig.MinimumIncidents, flagVars.verbose, config.OutputDirectory)
+	// the decisions made with review are applied last and win over the reference file
+	if flagVars.correctionsFilename != "" {
+		corrections, err := readCorrections(flagVars.correctionsFilename)
+		if err != nil {
+			return nil, nil, &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+		}
+		incidents = applyCorrections(incidents, corrections)
+	}
+
+	// auditors need to know why an incident is excluded or corrected, in the months of the report;
+	// older corrections may predate the reasons and do not count anymore
+	if missing := incidentsWithoutReason(incidents.SixMonthsIncidents(month, year)); len(missing) > 0 {
+		switch config.MissingReason {
+		case "ignore":
+		case "error":
+			return nil, nil, fmt.Errorf("%w: %s", ErrMissingReason, strings.Join(missing, ", "))
+		default:
+			log.Printf("Warning: excluded or corrected incidents without a reason: %s", strings.Join(missing, ", "))
+		}
+	}
+	return incidents, warnings, nil
 }
 
-// check if the command line contains a specific command (verb)
-func hasCommand(command string) bool {
-	// check is enough args
-	if len(flag.Args()) < 1 {
-		return false
+// getReferenceFilenames splits the comma separated reference workbooks
+// if a name equals to 'same' use the same name as the output
+// if a name equals to 'previous' or 'prev' use the xlsx from last month
+func getReferenceFilenames(reference string, country string, month int, year int) []string {
+	var filenames []string
+	for _, referenceFilename := range strings.Split(reference, ",") {
+		referenceFilename = strings.TrimSpace(referenceFilename)
+		if referenceFilename == "same" {
+			referenceFilename = getFilename(country, month, year)
+		} else if referenceFilename == "previous" || referenceFilename == "prev" {
+			prevMonth, prevYear := sla.PreviousMonth(month, year)
+			referenceFilename = getFilename(country, prevMonth, prevYear)
+		}
+		filenames = append(filenames, referenceFilename)
 	}
+	return filenames
+}
 
-	// check if arg is an option
-	if strings.HasPrefix(flag.Args()[0], "-") {
-		return false
+// outputFormat returns the -format of the command line, or the default of the command if none was given
+func outputFormat(defaultFormat string) string {
+	if flagVars.format == "" {
+		return defaultFormat
 	}
+	return flagVars.format
+}
 
-	// check if command is given
-	return flag.Args()[0] == command
+// metricsMonth returns the month the metrics are exported for: the -month and -year if given,
+// otherwise the current month at the time of the call, so a long running server follows the calendar
+func metricsMonth() (int, int) {
+	if monthOnCommandLine {
+		return flagVars.month, flagVars.year
+	}
+	now := time.Now()
+	return int(now.Month()), now.Year()
 }
 
-// used after the command is recognized, check for a noun
+// hasNoun checks the noun following the command
 // example ./goreport list countries
 // list is command, countries is noun
 func hasNoun(noun string) bool {
-	// check is enough args
-	if len(flag.Args()) < 2 {
-		return false
-	}
-
-	// check if arg is an option
-	if strings.HasPrefix(flag.Args()[1], "-") {
-		return false
-	}
-
-	// check if command is given
-	return flag.Args()[1] == noun
+	return len(commandArgs) > 0 && commandArgs[0] == noun
 }
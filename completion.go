@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completionShells are the shells a completion script can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// runCompletionCommand prints the completion script of the shell, generated from the commands and their flags
+func runCompletionCommand(out io.Writer) error {
+	switch commandArgs[0] {
+	case "bash":
+		writeBashCompletion(out)
+	case "zsh":
+		writeZshCompletion(out)
+	case "fish":
+		writeFishCompletion(out)
+	}
+	return nil
+}
+
+// fileFlags are the flags that take a file name, their values complete to files
+var fileFlags = []string{"cfg", "input", "output", "reference", "corrections"}
+
+// valueFlags returns the flags that take a value as a pattern for a case statement, e.g. -cfg|-input
+func valueFlags() string {
+	var names []string
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		if !isBoolFlag(f) {
+			names = append(names, "-"+f.Name)
+		}
+	})
+	return strings.Join(names, "|")
+}
+
+// completionWords returns the nouns and flags that can follow the command
+func completionWords(cmd *command) []string {
+	words := append([]string{}, cmd.nouns...)
+	if cmd.name == "help" {
+		for _, other := range commands {
+			words = append(words, other.name)
+		}
+	}
+	for _, name := range cmd.flagNames() {
+		words = append(words, "-"+name)
+	}
+	return words
+}
+
+func writeBashCompletion(out io.Writer) {
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	var globals []string
+	for _, name := range globalFlags {
+		globals = append(globals, "-"+name)
+	}
+
+	fmt.Fprintf(out, `# bash completion for goreport, load it with: source <(goreport completion bash)
+_goreport() {
+    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} command="" i
+    case $prev in
+        %s) return ;;
+    esac
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case ${COMP_WORDS[i]} in
+            %s) ((i++)) ;;
+            -*) ;;
+            *) command=${COMP_WORDS[i]}; break ;;
+        esac
+    done
+    local words
+    case $command in
+        "") words="%s %s" ;;
+`, valueFlags(), valueFlags(), strings.Join(names, " "), strings.Join(globals, " "))
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "        %s) words=\"%s\" ;;\n", cmd.name, strings.Join(completionWords(cmd), " "))
+	}
+	fmt.Fprint(out, `    esac
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -o default -F _goreport goreport
+`)
+}
+
+func writeZshCompletion(out io.Writer) {
+	fmt.Fprint(out, `#compdef goreport
+# zsh completion for goreport, load it with: source <(goreport completion zsh)
+_goreport() {
+    local -a commands
+    commands=(
+`)
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "        %s\n", zshQuote(cmd.name+":"+cmd.summary))
+	}
+	fmt.Fprintf(out, `    )
+    local command i
+    case ${words[CURRENT-1]} in
+        %s) _files; return ;;
+        %s) return ;;
+    esac
+    for ((i = 2; i < CURRENT; i++)); do
+        case ${words[i]} in
+            %s) ((i++)) ;;
+            -*) ;;
+            *) command=${words[i]}; break ;;
+        esac
+    done
+    case $command in
+        "") _describe 'command' commands; compadd -- %s ;;
+`, "-"+strings.Join(fileFlags, "|-"), valueFlags(), valueFlags(), "-"+strings.Join(globalFlags, " -"))
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "        %s) compadd -- %s ;;\n", cmd.name, strings.Join(completionWords(cmd), " "))
+	}
+	fmt.Fprint(out, `    esac
+}
+compdef _goreport goreport
+`)
+}
+
+func writeFishCompletion(out io.Writer) {
+	fmt.Fprint(out, "# fish completion for goreport, save it as ~/.config/fish/completions/goreport.fish\n")
+	fmt.Fprint(out, "complete -c goreport -f\n")
+	for _, name := range globalFlags {
+		fmt.Fprintf(out, "complete -c goreport %s\n", fishFlag(name, nil))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "complete -c goreport -n __fish_use_subcommand -a %s -d %s\n", cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range commands {
+		condition := fishQuote("__fish_seen_subcommand_from " + cmd.name)
+		if len(cmd.nouns) > 0 {
+			fmt.Fprintf(out, "complete -c goreport -n %s -a %s\n", condition, fishQuote(strings.Join(cmd.nouns, " ")))
+		}
+		if cmd.name == "help" {
+			var names []string
+			for _, other := range commands {
+				names = append(names, other.name)
+			}
+			fmt.Fprintf(out, "complete -c goreport -n %s -a %s\n", condition, fishQuote(strings.Join(names, " ")))
+		}
+		for _, name := range cmd.flags {
+			fmt.Fprintf(out, "complete -c goreport -n %s %s\n", condition, fishFlag(name, cmd.usages))
+		}
+	}
+}
+
+// fishFlag returns the options of fish's complete for a flag, described by its usage for the command
+func fishFlag(name string, usages map[string]string) string {
+	f := flag.CommandLine.Lookup(name)
+	usage, found := usages[name]
+	if !found {
+		usage = f.Usage
+	}
+	options := "-o " + name + " -d " + fishQuote(usage)
+	if containsString(fileFlags, name) {
+		options += " -r -F"
+	} else if !isBoolFlag(f) {
+		options += " -x"
+	}
+	return options
+}
+
+func fishQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func zshQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -1,30 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"time"
 
+	"github.com/ronaldlens/goreport/sla"
 	"gopkg.in/yaml.v2"
 )
 
-// SLA struct in the configuration file
-// Either Hours or Days has a value
-type SLA struct {
-	Priority string
-	Hours    int
-	Days     int
-}
-
-// MinimumIncidents used for TTR performance measurement
-// If minimum not reached, value will carry over to the next month
-type MinimumIncidents struct {
-	Critical int
-	High     int
-	Medium   int
-	Low      int
-}
-
 // Country struct holds the configuration for a given country
 type Country struct {
 	Name                 string
@@ -32,17 +17,37 @@ type Country struct {
 	ITServiceWindow      string
 	ITServiceWindowStart time.Time
 	ITServiceWindowEnd   time.Time
-	SLAs                 []SLA
-	MinimumIncidents     MinimumIncidents
+	SLAs                 []sla.SLA
+	MinimumIncidents     sla.MinimumIncidents
 	FilterOutCategories  []string
+	CategoryFilters      []sla.CategoryFilter
+	Recipients           []string
+}
+
+// SMTP struct holds the mail server used to send reports
+// Subject and Body are templates, if empty a default is used
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Subject  string
+	Body     string
 }
 
 // Config struct contains the overall configuration
 // Default country is optional
+// MissingReason is what to do with excluded or corrected incidents without a reason: warn (default), error or ignore
+// ReferencePrecedence decides conflicts between reference workbooks: first (default), last or error
 type Config struct {
-	DefaultCountry  string
-	OutputDirectory string
-	Countries       []Country
+	DefaultCountry      string
+	OutputDirectory     string
+	MissingReason       string
+	ReferencePrecedence string
+	SMTP                SMTP
+	Rules               []sla.Rule
+	Countries           []Country
 }
 
 func readConfig(filename string) (Config, error) {
@@ -59,15 +64,61 @@ func readConfig(filename string) (Config, error) {
 	if err != nil {
 		return config, err
 	}
+
+	switch config.MissingReason {
+	case "", "warn", "error", "ignore":
+	default:
+		return config, fmt.Errorf("invalid value for missingreason: %s, use warn, error or ignore", config.MissingReason)
+	}
+	switch config.ReferencePrecedence {
+	case "", "first", "last", "error":
+	default:
+		return config, fmt.Errorf("invalid value for referenceprecedence: %s, use first, last or error", config.ReferencePrecedence)
+	}
+	for index := range config.Rules {
+		if err := config.Rules[index].Compile(); err != nil {
+			return config, err
+		}
+	}
+	for index := range config.Countries {
+		if err := config.Countries[index].compileCategoryFilters(); err != nil {
+			return config, err
+		}
+	}
 	return config, nil
 }
 
-func getCountryFromConfig(config Config, countryName string) Country {
+// compileCategoryFilters prepares the patterns of the category filters,
+// the categories of FilterOutCategories are added as an exclude filter on Tier 2 for the whole country
+func (country *Country) compileCategoryFilters() error {
+	if len(country.FilterOutCategories) > 0 {
+		filter := sla.CategoryFilter{}
+		for _, category := range country.FilterOutCategories {
+			filter.Exclude = append(filter.Exclude, sla.CategoryPattern{Tier2: category})
+		}
+		country.CategoryFilters = append(country.CategoryFilters, filter)
+	}
+
+	if err := sla.CompileCategoryFilters(country.CategoryFilters); err != nil {
+		return fmt.Errorf("country %s: %v", country.Name, err)
+	}
+	return nil
+}
+
+func getCountryFromConfig(config Config, countryName string) (Country, error) {
+	country, found := findCountryInConfig(config, countryName)
+	if !found {
+		return Country{}, fmt.Errorf("%w: %s", ErrCountryNotFound, countryName)
+	}
+	return country, nil
+}
+
+// findCountryInConfig looks up a country in the configuration, the boolean is false if it is not configured
+func findCountryInConfig(config Config, countryName string) (Country, bool) {
 	for _, country := range config.Countries {
 		if country.Name == countryName {
-			return country
+			return country, true
 		}
 	}
-	log.Fatalf("Cannot find country %s in configuration", countryName)
-	return Country{}
+	return Country{}, false
 }
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+	"gopkg.in/yaml.v2"
+)
+
+// correctionDateFormat is the format of the date of a correction
+const correctionDateFormat = "2006-01-02"
+
+// correctionsCSVHeader are the columns of a corrections file in CSV format
+var correctionsCSVHeader = []string{"id", "exclude", "correctedtime", "reason", "reviewer", "date"}
+
+// Correction holds the decision on an incident, keyed by the incident ID
+// an incident that was reviewed and left as is only has a reason
+type Correction struct {
+	ID            string
+	Exclude       bool   `yaml:",omitempty"`
+	CorrectedTime string `yaml:",omitempty"`
+	Reason        string `yaml:",omitempty"`
+	Reviewer      string `yaml:",omitempty"`
+	Date          string `yaml:",omitempty"`
+}
+
+// Corrections is the content of the corrections file
+type Corrections struct {
+	Corrections []Correction
+}
+
+// readCorrections loads the corrections file, CSV if the extension is .csv and YAML otherwise
+// a file that does not exist yet has no corrections
+func readCorrections(filename string) (Corrections, error) {
+	corrections := Corrections{}
+	dat, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return corrections, nil
+	}
+	if err != nil {
+		return corrections, err
+	}
+
+	if isCSVFilename(filename) {
+		corrections.Corrections, err = parseCorrectionsCSV(dat)
+	} else {
+		err = yaml.Unmarshal(dat, &corrections)
+	}
+	if err != nil {
+		return corrections, err
+	}
+
+	seen := make(map[string]bool)
+	for _, correction := range corrections.Corrections {
+		if correction.ID == "" {
+			return corrections, fmt.Errorf("correction without incident ID")
+		}
+		if seen[correction.ID] {
+			return corrections, fmt.Errorf("incident %s has more than one correction", correction.ID)
+		}
+		seen[correction.ID] = true
+		if correction.CorrectedTime != "" {
+			if _, err := time.ParseDuration(correction.CorrectedTime); err != nil {
+				return corrections, fmt.Errorf("invalid corrected time '%s' for incident %s", correction.CorrectedTime, correction.ID)
+			}
+		}
+		if correction.Date != "" {
+			if _, err := time.Parse(correctionDateFormat, correction.Date); err != nil {
+				return corrections, fmt.Errorf("invalid date '%s' for incident %s", correction.Date, correction.ID)
+			}
+		}
+	}
+	return corrections, nil
+}
+
+// writeCorrections saves the corrections sorted by incident ID, so the file diffs well
+func writeCorrections(filename string, corrections Corrections) error {
+	sort.Slice(corrections.Corrections, func(i, j int) bool {
+		return corrections.Corrections[i].ID < corrections.Corrections[j].ID
+	})
+
+	var dat []byte
+	var err error
+	if isCSVFilename(filename) {
+		dat, err = formatCorrectionsCSV(corrections.Corrections)
+	} else {
+		dat, err = yaml.Marshal(corrections)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, dat, 0644)
+}
+
+// parseCorrectionsCSV reads corrections with a header row, the columns can be in any order
+// and only the id column is required
+func parseCorrectionsCSV(dat []byte) ([]Correction, error) {
+	reader := csv.NewReader(bytes.NewReader(dat))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for index, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	if _, found := columns["id"]; !found {
+		return nil, fmt.Errorf("no id column in the header")
+	}
+	get := func(record []string, column string) string {
+		index, found := columns[column]
+		if !found || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var corrections []Correction
+	for line, record := range records[1:] {
+		correction := Correction{
+			ID:            get(record, "id"),
+			CorrectedTime: get(record, "correctedtime"),
+			Reason:        get(record, "reason"),
+			Reviewer:      get(record, "reviewer"),
+			Date:          get(record, "date"),
+		}
+		if exclude := get(record, "exclude"); exclude != "" {
+			correction.Exclude, err = strconv.ParseBool(exclude)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value for exclude: %s", line+2, exclude)
+			}
+		}
+		corrections = append(corrections, correction)
+	}
+	return corrections, nil
+}
+
+func formatCorrectionsCSV(corrections []Correction) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	_ = writer.Write(correctionsCSVHeader)
+	for _, correction := range corrections {
+		_ = writer.Write([]string{correction.ID, strconv.FormatBool(correction.Exclude), correction.CorrectedTime,
+			correction.Reason, correction.Reviewer, correction.Date})
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+func isCSVFilename(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".csv")
+}
+
+// find returns the correction of an incident
+func (corrections *Corrections) find(id string) (Correction, bool) {
+	for _, correction := range corrections.Corrections {
+		if correction.ID == id {
+			return correction, true
+		}
+	}
+	return Correction{}, false
+}
+
+// set adds the correction or replaces the one of the same incident
+func (corrections *Corrections) set(correction Correction) {
+	for index := range corrections.Corrections {
+		if corrections.Corrections[index].ID == correction.ID {
+			corrections.Corrections[index] = correction
+			return
+		}
+	}
+	corrections.Corrections = append(corrections.Corrections, correction)
+}
+
+// importReferenceCorrections adds the adjusted incidents of report workbooks that are not in the corrections yet,
+// it returns the number of corrections added
+func importReferenceCorrections(corrections *Corrections, referenceCorrections []Correction, date time.Time) int {
+	added := 0
+	for _, correction := range referenceCorrections {
+		if !correction.adjusts() {
+			continue
+		}
+		if _, found := corrections.find(correction.ID); found {
+			continue
+		}
+		correction.Date = date.Format(correctionDateFormat)
+		corrections.set(correction)
+		added++
+	}
+	return added
+}
+
+// referenceConflict is an incident that two reference workbooks adjust differently
+type referenceConflict struct {
+	ID     string
+	First  string
+	Second string
+	Used   string
+
+	FirstCorrection  Correction
+	SecondCorrection Correction
+}
+
+// mergeReferenceCorrections combines the corrections read from several reference workbooks, in the order given.
+// An incident that is only adjusted in one workbook gets that adjustment. If two workbooks adjust it differently,
+// the precedence decides: "last" uses the later workbook, otherwise the first one is used. The conflicts are returned
+// to be reported, with precedence "error" the caller stops on them.
+func mergeReferenceCorrections(filenames []string, sets [][]Correction, precedence string) ([]Correction, []referenceConflict) {
+	var merged []Correction
+	var conflicts []referenceConflict
+	positions := make(map[string]int)
+	sources := make(map[string]int)
+
+	for setIndex, set := range sets {
+		for _, correction := range set {
+			position, found := positions[correction.ID]
+			if !found {
+				positions[correction.ID] = len(merged)
+				sources[correction.ID] = setIndex
+				merged = append(merged, correction)
+				continue
+			}
+
+			// an incident left as is in a workbook does not disagree with an adjustment in another one
+			existing := merged[position]
+			if !correction.adjusts() {
+				continue
+			}
+			if !existing.adjusts() {
+				merged[position] = correction
+				sources[correction.ID] = setIndex
+				continue
+			}
+			if existing.Exclude == correction.Exclude && existing.CorrectedTime == correction.CorrectedTime {
+				if existing.Reason == "" {
+					merged[position].Reason = correction.Reason
+					merged[position].Reviewer = correction.Reviewer
+				}
+				continue
+			}
+
+			conflict := referenceConflict{
+				ID:               correction.ID,
+				First:            filenames[sources[correction.ID]],
+				Second:           filenames[setIndex],
+				FirstCorrection:  existing,
+				SecondCorrection: correction,
+			}
+			conflict.Used = conflict.First
+			if precedence == "last" {
+				conflict.Used = conflict.Second
+				merged[position] = correction
+				sources[correction.ID] = setIndex
+			}
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return merged, conflicts
+}
+
+// describe returns the conflict in words, for the log and the report
+func (conflict *referenceConflict) describe() string {
+	return fmt.Sprintf("incident %s: %s in %s, %s in %s, using %s", conflict.ID,
+		conflict.FirstCorrection.describe(), conflict.First,
+		conflict.SecondCorrection.describe(), conflict.Second, conflict.Used)
+}
+
+// adjusts returns whether the correction excludes the incident or corrects its time
+func (correction *Correction) adjusts() bool {
+	return correction.Exclude || correction.CorrectedTime != ""
+}
+
+// describe returns the adjustment in words, for the conflict report
+func (correction *Correction) describe() string {
+	var parts []string
+	if correction.Exclude {
+		parts = append(parts, "excluded")
+	}
+	if correction.CorrectedTime != "" {
+		parts = append(parts, "corrected to "+correction.CorrectedTime)
+	}
+	if correction.Reviewer != "" {
+		parts = append(parts, "by "+correction.Reviewer)
+	}
+	return strings.Join(parts, " ")
+}
+
+// applyCorrections excludes incidents and sets their corrected time,
+// corrections that only have a reason leave the incident as is
+func applyCorrections(incidents sla.Incidents, corrections Corrections) sla.Incidents {
+	for _, correction := range corrections.Corrections {
+		if !correction.adjusts() {
+			continue
+		}
+		idx := findIncidentByID(incidents, correction.ID)
+		if idx == -1 {
+			continue
+		}
+		incidents[idx].Exclude = correction.Exclude
+		incidents[idx].SLAReady = !correction.Exclude && !incidents[idx].SolvedAt.IsZero()
+		incidents[idx].CorrectedTime = correction.CorrectedTime
+		incidents[idx].CorrectedOpenTime, _ = time.ParseDuration(correction.CorrectedTime)
+		incidents[idx].Reason = correction.Reason
+		incidents[idx].Reviewer = correction.Reviewer
+	}
+	return incidents
+}
+
+// incidentsWithoutReason returns the IDs of the excluded or corrected incidents that have no reason
+func incidentsWithoutReason(incidents sla.Incidents) []string {
+	var ids []string
+	for _, incident := range incidents {
+		if (incident.Exclude || incident.CorrectedTime != "") && strings.TrimSpace(incident.Reason) == "" {
+			ids = append(ids, incident.ID)
+		}
+	}
+	return ids
+}
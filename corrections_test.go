@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_readWriteCorrections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	corrections := Corrections{Corrections: []Correction{
+		{ID: "INC2", CorrectedTime: "3h30m", Reason: "waiting for customer, \"on hold\"", Reviewer: "jane", Date: "2019-11-04"},
+		{ID: "INC1", Exclude: true, Reason: "planned maintenance", Reviewer: "john", Date: "2019-11-01"},
+	}}
+	for _, name := range []string{"corrections.yaml", "corrections.csv"} {
+		filename := filepath.Join(dir, name)
+		if err := writeCorrections(filename, corrections); err != nil {
+			t.Fatal(err)
+		}
+		result, err := readCorrections(filename)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(result, corrections) {
+			t.Errorf("Expected %v from %s, got %v", corrections, name, result)
+		}
+	}
+
+	// a missing file has no corrections
+	if result, err := readCorrections(filepath.Join(dir, "missing.yaml")); err != nil || len(result.Corrections) != 0 {
+		t.Errorf("Expected no corrections for a missing file, got %v (%v)", result, err)
+	}
+
+	// columns in another order, missing columns and a duplicate incident
+	filename := filepath.Join(dir, "other.csv")
+	_ = ioutil.WriteFile(filename, []byte("exclude,ID\nyes,INC1\n"), 0644)
+	if _, err := readCorrections(filename); err == nil {
+		t.Errorf("Expected an error for exclude yes")
+	}
+	_ = ioutil.WriteFile(filename, []byte("exclude,ID\n1,INC1\nfalse,INC1\n"), 0644)
+	if _, err := readCorrections(filename); err == nil {
+		t.Errorf("Expected an error for a duplicate incident")
+	}
+	_ = ioutil.WriteFile(filename, []byte("exclude,ID\n1,INC1\n"), 0644)
+	result, err := readCorrections(filename)
+	if err != nil || len(result.Corrections) != 1 || !result.Corrections[0].Exclude {
+		t.Errorf("Expected INC1 to be excluded, got %v (%v)", result, err)
+	}
+}
+
+func Test_parseReferenceRows(t *testing.T) {
+	rows := [][]string{
+		{"ID", "Created", "Solved", "Time Open", "Exclude", "Corrected Open", "Priority", "Reason"},
+		{"INC1", "", "", "60", "TRUE", "", "High", "duplicate"},
+		{"INC2", "", "", "60", "no", "1h30m"},
+		{"INC3", "", "", "60"},
+		{"INC4", "", "", "60", "0"},
+	}
+	corrections, err := parseReferenceRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Correction{
+		{ID: "INC1", Exclude: true, Reason: "duplicate"},
+		{ID: "INC2", CorrectedTime: "1h30m"},
+		{ID: "INC3"},
+		{ID: "INC4"},
+	}
+	if !reflect.DeepEqual(corrections, expected) {
+		t.Errorf("Expected %v, got %v", expected, corrections)
+	}
+
+	// an exclusion by a rule is not read back, unless a reviewer took it over
+	ruleRows := [][]string{
+		{"ID", "Exclude", "Corrected Open", "Reason", "Reviewer", "Rule"},
+		{"INC1", "TRUE", "", "excluded by rule maintenance", "", "maintenance"},
+		{"INC2", "TRUE", "", "excluded by rule maintenance", "jane", "maintenance"},
+		{"INC3", "TRUE", "", "duplicate", "", "reclassify crm"},
+	}
+	corrections, err = parseReferenceRows(ruleRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []Correction{
+		{ID: "INC1"},
+		{ID: "INC2", Exclude: true, Reason: "excluded by rule maintenance", Reviewer: "jane"},
+		{ID: "INC3", Exclude: true, Reason: "duplicate"},
+	}
+	if !reflect.DeepEqual(corrections, expected) {
+		t.Errorf("Expected %v, got %v", expected, corrections)
+	}
+
+	rows[2][4] = "maybe"
+	_, err = parseReferenceRows(rows)
+	var rowErr *ReferenceRowError
+	if !errors.As(err, &rowErr) {
+		t.Errorf("Expected a row error for exclude maybe, got %v", err)
+	} else if rowErr.Row != 3 || rowErr.ID != rows[2][0] {
+		t.Errorf("Expected row 3 of incident %s, got row %d of incident %s", rows[2][0], rowErr.Row, rowErr.ID)
+	}
+	if _, err := parseReferenceRows([][]string{{"ID", "Exclude"}}); err == nil {
+		t.Errorf("Expected an error for a missing Corrected Open column")
+	}
+}
+
+func Test_mergeReferenceCorrections(t *testing.T) {
+	filenames := []string{"it.xlsx", "network.xlsx"}
+	sets := [][]Correction{
+		{{ID: "INC1", Exclude: true, Reviewer: "jane"}, {ID: "INC2"}, {ID: "INC3", CorrectedTime: "1h"}, {ID: "INC4", Exclude: true}},
+		{{ID: "INC1", CorrectedTime: "2h", Reviewer: "john"}, {ID: "INC2", Exclude: true}, {ID: "INC3"}, {ID: "INC4", Exclude: true, Reason: "duplicate"}},
+	}
+
+	merged, conflicts := mergeReferenceCorrections(filenames, sets, "first")
+	expected := []Correction{
+		{ID: "INC1", Exclude: true, Reviewer: "jane"},
+		{ID: "INC2", Exclude: true},
+		{ID: "INC3", CorrectedTime: "1h"},
+		{ID: "INC4", Exclude: true, Reason: "duplicate"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != "INC1" || conflicts[0].Used != "it.xlsx" {
+		t.Errorf("Expected a conflict on INC1 using it.xlsx, got %v", conflicts)
+	}
+
+	merged, conflicts = mergeReferenceCorrections(filenames, sets, "last")
+	if merged[0].CorrectedTime != "2h" || merged[0].Exclude || len(conflicts) != 1 || conflicts[0].Used != "network.xlsx" {
+		t.Errorf("Expected INC1 corrected to 2h from network.xlsx, got %v and %v", merged[0], conflicts)
+	}
+}
+
+func Test_incidentsWithoutReason(t *testing.T) {
+	incidents := sla.Incidents{
+		{ID: "1", CorrectedTime: "1h"},
+		{ID: "2", Exclude: true},
+		{ID: "3", Exclude: true, Reason: "maintenance"},
+		{ID: "4"},
+	}
+	if missing := incidentsWithoutReason(incidents); strings.Join(missing, ",") != "1,2" {
+		t.Errorf("Expected incidents 1 and 2 without reason, got %v", missing)
+	}
+
+	// only the adjustments in the months of the report need a reason
+	config = Config{MissingReason: "error"}
+	flagVars.referenceFilename, flagVars.correctionsFilename = "", ""
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents = sla.Incidents{
+		{ID: "1", Exclude: true, CreatedAt: created.AddDate(-1, 0, 0)},
+		{ID: "2", Exclude: true, Reason: "maintenance", CreatedAt: created},
+	}
+	if _, _, err := adjustIncidents(incidents, "Sweden", 10, 2019); err != nil {
+		t.Errorf("Expected no error for an old exclusion without reason, got %v", err)
+	}
+	incidents[1].Reason = ""
+	if _, _, err := adjustIncidents(incidents, "Sweden", 10, 2019); !errors.Is(err, ErrMissingReason) {
+		t.Errorf("Expected ErrMissingReason for an exclusion in the report months, got %v", err)
+	}
+	config = Config{}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// dashboardCountry is a row on the dashboard index page
+type dashboardCountry struct {
+	Name string
+	Link string
+}
+
+// dashboardIndexPage is passed to the index template
+type dashboardIndexPage struct {
+	Title     string
+	Countries []dashboardCountry
+}
+
+// dashboardArea contains the tables and charts of a (business) area on the country page
+type dashboardArea struct {
+	Title             string
+	Performance       htmlTable
+	PerformanceChart  template.HTML
+	TotalChart        template.HTML
+	Availability      *htmlTable
+	AvailabilityChart template.HTML
+}
+
+// dashboardCountryPage is passed to the country template
+type dashboardCountryPage struct {
+	Title        string
+	PreviousLink string
+	NextLink     string
+	Areas        []dashboardArea
+}
+
+// dashboardIncidentsPage is passed to the incidents template
+type dashboardIncidentsPage struct {
+	Title     string
+	BackLink  string
+	Incidents htmlTable
+}
+
+// the templates are part of the binary, the pages do not load anything from other sites
+var (
+	dashboardIndexTemplate     = template.Must(template.New("index").Parse(dashboardHeader + dashboardIndexBody + dashboardFooter))
+	dashboardCountryTemplate   = template.Must(template.New("country").Parse(dashboardHeader + dashboardCountryBody + dashboardFooter))
+	dashboardIncidentsTemplate = template.Must(template.New("incidents").Parse(dashboardHeader + dashboardIncidentsBody + dashboardFooter))
+)
+
+func (srv *server) registerDashboard(mux *http.ServeMux) {
+	mux.HandleFunc("/", srv.handleDashboardIndex)
+	mux.HandleFunc("/dashboard", srv.handleDashboardCountry)
+	mux.HandleFunc("/dashboard/incidents", srv.handleDashboardIncidents)
+}
+
+// handleDashboardIndex lists the configured countries
+func (srv *server) handleDashboardIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page := dashboardIndexPage{Title: "goreport"}
+	for _, country := range config.Countries {
+		page.Countries = append(page.Countries, dashboardCountry{
+			Name: country.Name,
+			Link: dashboardLink("/dashboard", country.Name, flagVars.month, flagVars.year, nil),
+		})
+	}
+	sort.Slice(page.Countries, func(i, j int) bool { return page.Countries[i].Name < page.Countries[j].Name })
+	renderDashboard(w, dashboardIndexTemplate, page)
+}
+
+// handleDashboardCountry shows the SLA performance and availability of a country for the 6 months of the report
+// cells below target link to the incidents causing it
+func (srv *server) handleDashboardCountry(w http.ResponseWriter, r *http.Request) {
+	countryConfig, month, year, err := parseReportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := prepareReportData(srv.getIncidents(), countryConfig, month, year)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prevMonth, prevYear := sla.PreviousMonth(month, year)
+	nextMonth, nextYear := sla.NextMonth(month, year)
+	page := dashboardCountryPage{
+		Title:        fmt.Sprintf("%s %s %d", data.Country, sla.MonthNames[month], year),
+		PreviousLink: dashboardLink("/dashboard", data.Country, prevMonth, prevYear, nil),
+		NextLink:     dashboardLink("/dashboard", data.Country, nextMonth, nextYear, nil),
+	}
+
+	months := data.MonthNames()
+	for _, areaData := range data.Areas {
+		area := dashboardArea{Title: "Overview"}
+		if areaData.Name != "" {
+			area.Title += " " + areaData.Name
+		}
+		area.Performance = newDashboardPerformanceTable(&data, areaData)
+		totalSeries, performanceSeries := newChartSeries(areaData)
+		area.TotalChart = svgLineChart("Total Incidents", months, totalSeries, false)
+		area.PerformanceChart = svgLineChart("SLA Performance", months, performanceSeries, true)
+		if len(areaData.Availability) > 0 {
+			table := newDashboardAvailabilityTable(&data, areaData)
+			area.Availability = &table
+			area.AvailabilityChart = newAvailabilityChart(areaData, months)
+		}
+		page.Areas = append(page.Areas, area)
+	}
+	renderDashboard(w, dashboardCountryTemplate, page)
+}
+
+// handleDashboardIncidents lists the incidents behind a cell of the country page
+// the query contains the month, the area and either the priority or the service
+func (srv *server) handleDashboardIncidents(w http.ResponseWriter, r *http.Request) {
+	countryConfig, month, year, err := parseReportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	incidents, _, err := prepareIncidents(srv.getIncidents(), countryConfig, month, year)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	incidents = incidents.FilterByMonthYear(month, year)
+
+	title := fmt.Sprintf("%s %s %d", countryConfig.Name, sla.MonthNames[month], year)
+	if area := query.Get("area"); area != "" {
+		incidents = incidents.FilterByBusinessArea(area)
+		title += " " + area
+	}
+	if priority := query.Get("priority"); priority != "" {
+		id, err := sla.ParsePriority(priority)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		incidents = incidents.FilterByPriority(id)
+		title += " " + priority
+	}
+	if service := query.Get("service"); service != "" {
+		incidents = incidents.FilterByService(service)
+		title += " " + service
+	}
+	if query.Get("breached") == "true" {
+		var breached sla.Incidents
+		for _, incident := range incidents {
+			if incident.SLAReady && !incident.SLAMet {
+				breached = append(breached, incident)
+			}
+		}
+		incidents = breached
+		title += " SLA breached"
+	}
+
+	page := dashboardIncidentsPage{
+		Title:     title,
+		BackLink:  dashboardLink("/dashboard", countryConfig.Name, month, year, nil),
+		Incidents: newHTMLIncidentsTable(incidents),
+	}
+	renderDashboard(w, dashboardIncidentsTemplate, page)
+}
+
+// newDashboardPerformanceTable returns the SLA performance table, red cells link to the breached incidents
+func newDashboardPerformanceTable(data *sla.ReportData, areaData sla.AreaData) htmlTable {
+	table := htmlTable{Title: "SLA Performance", Header: append([]string{"Priority", "Target"}, data.MonthNames()...)}
+	for _, priorityData := range areaData.Priorities {
+		row := []htmlCell{{Value: priorityData.Priority}, {Value: formatPercentage(priorityData.Target, 0)}}
+		for index, monthData := range priorityData.Months {
+			percentage, ok := monthData.Percentage()
+			if !ok {
+				row = append(row, htmlCell{})
+				continue
+			}
+			cell := newHTMLPercentageCell(percentage, 0, priorityData.Target)
+			if monthData.Total != monthData.SLAMet {
+				cell.Link = dashboardLink("/dashboard/incidents", data.Country, data.Months[index].Month, data.Months[index].Year,
+					url.Values{"area": {areaData.Name}, "priority": {priorityData.Priority}, "breached": {"true"}})
+			}
+			row = append(row, cell)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// newDashboardAvailabilityTable returns the availability table, red cells link to the critical incidents of the service
+func newDashboardAvailabilityTable(data *sla.ReportData, areaData sla.AreaData) htmlTable {
+	table := htmlTable{Title: "IT Service Availability", Header: append([]string{"Service", "Target"}, data.MonthNames()...)}
+	for _, service := range areaData.Availability {
+		row := []htmlCell{{Value: service.Service}, {Value: formatPercentage(service.Target, 2)}}
+		for index, value := range service.Months {
+			cell := newHTMLPercentageCell(value, 2, service.Target)
+			if value < service.Target {
+				cell.Link = dashboardLink("/dashboard/incidents", data.Country, data.Months[index].Month, data.Months[index].Year,
+					url.Values{"area": {areaData.Name}, "priority": {sla.PriorityNames[sla.Critical]}, "service": {service.Service}})
+			}
+			row = append(row, cell)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// newAvailabilityChart draws the availability of the services, the y axis starts just below the lowest value
+func newAvailabilityChart(areaData sla.AreaData, months []string) template.HTML {
+	minValue := sla.AvailabilityTarget - 0.005
+	var series []chartSeries
+	for _, service := range areaData.Availability {
+		s := chartSeries{Name: service.Service, Values: service.Months}
+		for _, value := range service.Months {
+			s.Valid = append(s.Valid, true)
+			minValue = math.Min(minValue, math.Floor(value*200)/200)
+		}
+		series = append(series, s)
+	}
+	return svgLineChartRange("Availability", months, series, true, minValue, 1)
+}
+
+// dashboardLink returns the link to a dashboard page for a country and month with extra query parameters
+func dashboardLink(path string, country string, month int, year int, values url.Values) string {
+	if values == nil {
+		values = url.Values{}
+	}
+	values.Set("country", country)
+	values.Set("month", strconv.Itoa(month))
+	values.Set("year", strconv.Itoa(year))
+	return path + "?" + values.Encode()
+}
+
+func renderDashboard(w http.ResponseWriter, tmpl *template.Template, page interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+const dashboardHeader = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+` + htmlStyle + `<style>
+nav { margin-bottom: 1em; }
+nav a { margin-right: 1em; }
+</style>
+</head>
+<body>
+<nav><a href="/">Countries</a></nav>
+` + htmlTableTemplate
+
+const dashboardFooter = htmlScript + `</body>
+</html>
+`
+
+const dashboardIndexBody = `
+<h1>Countries</h1>
+<ul>
+{{range .Countries}}<li><a href="{{.Link}}">{{.Name}}</a></li>
+{{end}}</ul>
+`
+
+const dashboardCountryBody = `
+<h1>{{.Title}}</h1>
+<nav><a href="{{.PreviousLink}}">&larr; previous month</a><a href="{{.NextLink}}">next month &rarr;</a></nav>
+{{range .Areas}}
+<h2>{{.Title}}</h2>
+<div class="charts">{{.PerformanceChart}}{{.TotalChart}}</div>
+<h3>{{.Performance.Title}}</h3>
+{{template "table" .Performance}}
+{{if .Availability}}
+<div class="charts">{{.AvailabilityChart}}</div>
+<h3>{{.Availability.Title}}</h3>
+{{template "table" .Availability}}
+{{end}}
+{{end}}
+`
+
+const dashboardIncidentsBody = `
+<h1>{{.Title}}</h1>
+<nav><a href="{{.BackLink}}">&larr; back</a></nav>
+<input class="filter" type="search" placeholder="Filter incidents" data-table="incidents">
+{{template "table" .Incidents}}
+`
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// exit codes, one for each class of error
+const (
+	exitFailure    = 1 // writing the output, sending mail and anything not classified below
+	exitUsage      = 2 // the command line cannot be run
+	exitConfig     = 3 // the configuration file is invalid or lacks the country
+	exitInput      = 4 // the incidents cannot be read or contain invalid data
+	exitAdjustment = 5 // the reference workbooks or corrections file cannot be used
+)
+
+// ErrCountryNotFound is returned for a country that is not in the configuration file
+var ErrCountryNotFound = errors.New("country not found in configuration")
+
+// ErrReferenceConflict is returned when reference workbooks adjust incidents differently,
+// with referenceprecedence: error
+var ErrReferenceConflict = errors.New("incidents are adjusted differently in the reference files")
+
+// ErrMissingReason is returned for excluded or corrected incidents without a reason, with missingreason: error
+var ErrMissingReason = errors.New("excluded or corrected incidents without a reason")
+
+// UsageError is a command line that cannot be run
+type UsageError struct {
+	Message string
+}
+
+func (err *UsageError) Error() string {
+	return err.Message
+}
+
+// ConfigError is a configuration file that cannot be read or is invalid
+type ConfigError struct {
+	Filename string
+	Err      error
+}
+
+func (err *ConfigError) Error() string {
+	return fmt.Sprintf("configuration file %s: %v", err.Filename, err.Err)
+}
+
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+// InputError is an incidents file that cannot be read
+type InputError struct {
+	Filename string
+	Err      error
+}
+
+func (err *InputError) Error() string {
+	return fmt.Sprintf("importing %s: %v", err.Filename, err.Err)
+}
+
+func (err *InputError) Unwrap() error {
+	return err.Err
+}
+
+// AdjustmentError is a reference workbook or corrections file that cannot be read or written
+type AdjustmentError struct {
+	Filename string
+	Err      error
+}
+
+func (err *AdjustmentError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Filename, err.Err)
+}
+
+func (err *AdjustmentError) Unwrap() error {
+	return err.Err
+}
+
+// ReferenceRowError is an invalid row in the Incidents sheet of a reference workbook,
+// Row is the row number as shown in Excel
+type ReferenceRowError struct {
+	Filename string
+	Row      int
+	ID       string
+	Err      error
+}
+
+func (err *ReferenceRowError) Error() string {
+	message := fmt.Sprintf("row %d, incident %s: %v", err.Row, err.ID, err.Err)
+	if err.Filename != "" {
+		message = fmt.Sprintf("reference file %s, %s", err.Filename, message)
+	}
+	return message
+}
+
+func (err *ReferenceRowError) Unwrap() error {
+	return err.Err
+}
+
+// OutputError is a report, metrics file or mail that cannot be written or sent
+type OutputError struct {
+	Filename string
+	Err      error
+}
+
+func (err *OutputError) Error() string {
+	return fmt.Sprintf("writing %s: %v", err.Filename, err.Err)
+}
+
+func (err *OutputError) Unwrap() error {
+	return err.Err
+}
+
+// exitCode returns the exit code for the class of the error
+func exitCode(err error) int {
+	var usageErr *UsageError
+	var whereErr *sla.WhereError
+	var configErr *ConfigError
+	var inputErr *InputError
+	var incidentErr *sla.IncidentError
+	var adjustmentErr *AdjustmentError
+	var rowErr *ReferenceRowError
+
+	switch {
+	case errors.As(err, &usageErr), errors.As(err, &whereErr):
+		return exitUsage
+	case errors.Is(err, ErrCountryNotFound), errors.As(err, &configErr):
+		return exitConfig
+	case errors.As(err, &inputErr), errors.As(err, &incidentErr):
+		return exitInput
+	case errors.As(err, &adjustmentErr), errors.As(err, &rowErr),
+		errors.Is(err, ErrReferenceConflict), errors.Is(err, ErrMissingReason):
+		return exitAdjustment
+	}
+	return exitFailure
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_exitCode(t *testing.T) {
+	_, whereErr := sla.ParseWhere("priority ==")
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&UsageError{Message: "no command specified"}, exitUsage},
+		{fmt.Errorf("invalid -where expression: %w", whereErr), exitUsage},
+		{&ConfigError{Filename: "config.yaml", Err: errors.New("bad yaml")}, exitConfig},
+		{fmt.Errorf("%w: %s", ErrCountryNotFound, "Atlantis"), exitConfig},
+		{&InputError{Filename: "incidents.xlsx", Err: errors.New("no header")}, exitInput},
+		{fmt.Errorf("preparing: %w", &sla.IncidentError{ID: "INC1", Err: errors.New("bad time")}), exitInput},
+		{&ReferenceRowError{Filename: "ref.xlsx", Row: 3, ID: "INC1", Err: errors.New("bad")}, exitAdjustment},
+		{fmt.Errorf("%d %w", 2, ErrReferenceConflict), exitAdjustment},
+		{fmt.Errorf("%w: INC1", ErrMissingReason), exitAdjustment},
+		{&OutputError{Filename: "report.xlsx", Err: errors.New("disk full")}, exitFailure},
+		{errors.New("anything else"), exitFailure},
+	}
+	for _, test := range tests {
+		if got := exitCode(test.err); got != test.want {
+			t.Errorf("exitCode(%v) got %d, want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func Test_ReferenceRowError(t *testing.T) {
+	err := &ReferenceRowError{Row: 3, ID: "INC1", Err: errors.New("invalid value")}
+	if got, want := err.Error(), "row 3, incident INC1: invalid value"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	err.Filename = "ref.xlsx"
+	if got, want := err.Error(), "reference file ref.xlsx, row 3, incident INC1: invalid value"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
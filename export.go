@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// csvHeader is the header of the flat CSV export, every row contains a single value
+var csvHeader = []string{"schema_version", "country", "area", "metric", "key", "month", "year", "value"}
+
+// jsonRenderer writes the report data as JSON, it implements Renderer
+type jsonRenderer struct{}
+
+// csvRenderer writes the report data as CSV, it implements Renderer
+type csvRenderer struct{}
+
+// Render writes the report data as indented JSON
+func (jsonRenderer) Render(data *sla.ReportData, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// Render writes the report data as a flat CSV file with one value per row
+// the metrics are described in the README
+func (csvRenderer) Render(data *sla.ReportData, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	version := strconv.Itoa(data.SchemaVersion)
+	write := func(area string, metric string, key string, month *sla.ReportMonth, value string) {
+		monthStr, yearStr := "", ""
+		if month != nil {
+			monthStr, yearStr = strconv.Itoa(month.Month), strconv.Itoa(month.Year)
+		}
+		_ = writer.Write([]string{version, data.Country, area, metric, key, monthStr, yearStr, value})
+	}
+
+	_ = writer.Write(csvHeader)
+	for _, area := range data.Areas {
+		for _, priority := range area.Priorities {
+			write(area.Name, "sla_target", priority.Priority, nil, formatFloat(priority.Target))
+			for index, monthData := range priority.Months {
+				month := &data.Months[index]
+				write(area.Name, "total_incidents", priority.Priority, month, strconv.Itoa(monthData.Total))
+				write(area.Name, "sla_met_incidents", priority.Priority, month, strconv.Itoa(monthData.SLAMet))
+				write(area.Name, "calc_total_incidents", priority.Priority, month, strconv.Itoa(monthData.CalcTotal))
+				write(area.Name, "calc_sla_met_incidents", priority.Priority, month, strconv.Itoa(monthData.CalcSLAMet))
+				if monthData.Performance != nil {
+					write(area.Name, "sla_performance", priority.Priority, month, formatFloat(*monthData.Performance))
+				}
+			}
+		}
+		for _, service := range area.Availability {
+			write(area.Name, "availability_target", service.Service, nil, formatFloat(service.Target))
+			for index, value := range service.Months {
+				write(area.Name, "service_availability", service.Service, &data.Months[index], formatFloat(value))
+			}
+		}
+	}
+	for _, category := range data.ProdCategories {
+		write("", "prodcat_total", category.Name, nil, strconv.Itoa(category.Total))
+		write("", "prodcat_sla_met", category.Name, nil, strconv.Itoa(category.SLAMet))
+		write("", "prodcat_critical", category.Name, nil, strconv.Itoa(category.Critical))
+		write("", "prodcat_high", category.Name, nil, strconv.Itoa(category.High))
+		write("", "prodcat_medium", category.Name, nil, strconv.Itoa(category.Medium))
+		write("", "prodcat_low", category.Name, nil, strconv.Itoa(category.Low))
+	}
+	for _, priority := range data.Backlog.Priorities {
+		write("", "backlog_open", priority.Priority, nil, strconv.Itoa(priority.Total))
+		write("", "backlog_overdue", priority.Priority, nil, strconv.Itoa(priority.Overdue))
+		for index, count := range priority.Ages {
+			write("", "backlog_age_"+data.Backlog.Ages[index], priority.Priority, nil, strconv.Itoa(count))
+		}
+	}
+	for index, open := range data.Backlog.Trend {
+		write("", "backlog_trend", "", &data.Months[index], strconv.Itoa(open))
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
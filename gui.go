@@ -1,12 +1,428 @@
 package main
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	ui "github.com/VladimirMarkelov/clui"
+	"github.com/ronaldlens/goreport/sla"
 )
 
+// guiState holds the selection and the numbers shown in the gui
+type guiState struct {
+	incidents   sla.Incidents
+	country     Country
+	month       int
+	year        int
+	data        sla.ReportData
+	filter      string
+	visible     sla.Incidents
+	corrections Corrections
+	reviewer    string
+
+	overview      *ui.TableView
+	availability  *ui.TableView
+	incidentList  *ui.TableView
+	monthLabel    *ui.Label
+	statusLabel   *ui.Label
+	warningsLabel *ui.Label
+}
+
+// overview and availability rows, flattened over the areas
+type guiOverviewRow struct {
+	area     string
+	priority sla.PriorityData
+}
+
+type guiAvailabilityRow struct {
+	area    string
+	service sla.ServiceAvailabilityData
+}
+
 // RunGui starts the Gui
-func RunGui(incidents Incidents) {
+// it shows the numbers of a country and month and lets the user exclude incidents,
+// correct their open time and generate the report with those changes.
+// The changes are saved to the corrections file with a reason, like the decisions of review.
+func RunGui(incidents sla.Incidents) error {
+	country, err := getCountryFromConfig(config, flagVars.country)
+	if err != nil {
+		return err
+	}
+	corrections, err := readCorrections(flagVars.correctionsFilename)
+	if err != nil {
+		return &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+	}
+
 	ui.InitLibrary()
 	defer ui.DeinitLibrary()
 
+	gui := &guiState{
+		incidents:   incidents,
+		country:     country,
+		month:       flagVars.month,
+		year:        flagVars.year,
+		corrections: corrections,
+		reviewer:    reviewerName(),
+	}
+	gui.createView()
+	gui.refresh()
+
+	ui.MainLoop()
+	return nil
+}
+
+func (gui *guiState) createView() {
+	view := ui.AddWindow(0, 0, 140, 45, "goreport")
+	view.SetPack(ui.Horizontal)
+
+	// left column: country, month and actions
+	left := ui.CreateFrame(view, 26, ui.AutoSize, ui.BorderNone, ui.Fixed)
+	left.SetPack(ui.Vertical)
+	left.SetPaddings(1, 1)
+	left.SetGaps(ui.KeepValue, 1)
+
+	ui.CreateLabel(left, ui.AutoSize, ui.AutoSize, "Country", ui.Fixed)
+	countryList := ui.CreateListBox(left, 24, 10, ui.Fixed)
+	var names []string
+	for _, country := range config.Countries {
+		names = append(names, country.Name)
+	}
+	sort.Strings(names)
+	for index, name := range names {
+		countryList.AddItem(name)
+		if name == gui.country.Name {
+			countryList.SelectItem(index)
+		}
+	}
+	countryList.OnSelectItem(func(ev ui.Event) {
+		country, found := findCountryInConfig(config, countryList.SelectedItemText())
+		if found && country.Name != gui.country.Name {
+			gui.country = country
+			gui.refresh()
+		}
+	})
+
+	ui.CreateLabel(left, ui.AutoSize, ui.AutoSize, "Month", ui.Fixed)
+	monthFrame := ui.CreateFrame(left, ui.AutoSize, ui.AutoSize, ui.BorderNone, ui.Fixed)
+	monthFrame.SetPack(ui.Horizontal)
+	monthFrame.SetGaps(1, ui.KeepValue)
+	previous := ui.CreateButton(monthFrame, ui.AutoSize, 4, "<", ui.Fixed)
+	gui.monthLabel = ui.CreateLabel(monthFrame, 10, 1, "", ui.Fixed)
+	next := ui.CreateButton(monthFrame, ui.AutoSize, 4, ">", ui.Fixed)
+	previous.OnClick(func(ev ui.Event) {
+		gui.month, gui.year = sla.PreviousMonth(gui.month, gui.year)
+		gui.refresh()
+	})
+	next.OnClick(func(ev ui.Event) {
+		gui.month, gui.year = sla.NextMonth(gui.month, gui.year)
+		gui.refresh()
+	})
+
+	generate := ui.CreateButton(left, 24, 4, "Generate report", ui.Fixed)
+	generate.OnClick(func(ev ui.Event) {
+		gui.generateReport()
+	})
+	quit := ui.CreateButton(left, 24, 4, "Quit", ui.Fixed)
+	quit.OnClick(func(ev ui.Event) {
+		go ui.Stop()
+	})
+	gui.statusLabel = ui.CreateLabel(left, 24, 1, "", 1)
+
+	// right column: the tables
+	right := ui.CreateFrame(view, ui.AutoSize, ui.AutoSize, ui.BorderNone, 1)
+	right.SetPack(ui.Vertical)
+	right.SetPaddings(1, 1)
+
+	ui.CreateLabel(right, ui.AutoSize, ui.AutoSize, "SLA Performance", ui.Fixed)
+	gui.overview = ui.CreateTableView(right, ui.AutoSize, 11, ui.Fixed)
+	gui.overview.SetShowLines(true)
+	gui.overview.SetFullRowSelect(true)
+	gui.overview.OnDrawCell(gui.drawOverviewCell)
+
+	ui.CreateLabel(right, ui.AutoSize, ui.AutoSize, "IT Service Availability", ui.Fixed)
+	gui.availability = ui.CreateTableView(right, ui.AutoSize, 12, ui.Fixed)
+	gui.availability.SetShowLines(true)
+	gui.availability.SetFullRowSelect(true)
+	gui.availability.OnDrawCell(gui.drawAvailabilityCell)
+
+	filterFrame := ui.CreateFrame(right, ui.AutoSize, ui.AutoSize, ui.BorderNone, ui.Fixed)
+	filterFrame.SetPack(ui.Horizontal)
+	filterFrame.SetGaps(1, ui.KeepValue)
+	ui.CreateLabel(filterFrame, ui.AutoSize, ui.AutoSize, "Filter incidents", ui.Fixed)
+	filterEdit := ui.CreateEditField(filterFrame, 40, "", 1)
+	filterEdit.OnChange(func(ev ui.Event) {
+		gui.filter = filterEdit.Title()
+		gui.updateIncidentList()
+	})
+
+	gui.incidentList = ui.CreateTableView(right, ui.AutoSize, 10, 1)
+	gui.incidentList.SetShowLines(true)
+	gui.incidentList.SetFullRowSelect(true)
+	gui.incidentList.SetColumns([]ui.Column{
+		{Title: "ID", Width: 16},
+		{Title: "Created", Width: 16},
+		{Title: "Priority", Width: 8},
+		{Title: "Area", Width: 8},
+		{Title: "Service", Width: 16},
+		{Title: "Open", Width: 8, Alignment: ui.AlignRight},
+		{Title: "Corrected", Width: 9},
+		{Title: "Exclude", Width: 7},
+		{Title: "SLA Met", Width: 7},
+		{Title: "Description", Width: 40},
+	})
+	gui.incidentList.OnDrawCell(gui.drawIncidentCell)
+	gui.incidentList.OnAction(func(ev ui.TableEvent) {
+		if ev.Row < 0 || ev.Row >= len(gui.visible) {
+			return
+		}
+		switch ev.Action {
+		case ui.TableActionEdit:
+			gui.editCorrectedTime(gui.visible[ev.Row])
+		case ui.TableActionDelete:
+			gui.toggleExclude(gui.visible[ev.Row])
+		}
+	})
+
+	ui.CreateLabel(right, ui.AutoSize, ui.AutoSize,
+		"Enter: correct the open time (e.g. 3h30m, empty to reset)   Del: exclude or include the incident", ui.Fixed)
+	gui.warningsLabel = ui.CreateLabel(right, ui.AutoSize, ui.AutoSize, "", ui.Fixed)
+
+	ui.ActivateControl(view, gui.incidentList)
+}
+
+// refresh recalculates the numbers for the selected country and month with the adjustments of the
+// reference workbooks and the corrections file, on an error the status shows it and the previous numbers stay
+func (gui *guiState) refresh() {
+	data, err := prepareReportData(gui.incidents, gui.country, gui.month, gui.year)
+	if err != nil {
+		gui.setStatus(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	gui.data = data
+
+	gui.monthLabel.SetTitle(fmt.Sprintf("%s %d", sla.MonthNames[gui.month], gui.year))
+	gui.warningsLabel.SetTitle("")
+	if len(gui.data.Warnings) > 0 {
+		gui.warningsLabel.SetTitle("Warning: " + strings.Join(gui.data.Warnings, "; "))
+	}
+
+	columns := []ui.Column{{Title: "Area", Width: 8}, {Title: "Priority", Width: 8}, {Title: "Target", Width: 6, Alignment: ui.AlignRight}}
+	for _, name := range gui.data.MonthNames() {
+		columns = append(columns, ui.Column{Title: name, Width: 11, Alignment: ui.AlignRight})
+	}
+	gui.overview.SetColumns(columns)
+	gui.overview.SetRowCount(len(gui.overviewRows()))
+
+	columns = append([]ui.Column{{Title: "Area", Width: 8}, {Title: "Service", Width: 18}, {Title: "Target", Width: 7, Alignment: ui.AlignRight}},
+		columns[3:]...)
+	gui.availability.SetColumns(columns)
+	gui.availability.SetRowCount(len(gui.availabilityRows()))
+
+	gui.updateIncidentList()
+}
+
+// updateIncidentList shows the incidents of the report matching all words of the filter
+func (gui *guiState) updateIncidentList() {
+	terms := strings.Fields(strings.ToLower(gui.filter))
+	gui.visible = nil
+	for _, incident := range gui.data.Incidents {
+		text := strings.ToLower(strings.Join([]string{incident.ID, sla.PriorityNames[incident.Priority], incident.BusinessArea,
+			incident.Service, incident.ServiceCI, incident.ProdCategory1, incident.ProdCategory2, incident.Description}, " "))
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			gui.visible = append(gui.visible, incident)
+		}
+	}
+	gui.incidentList.SetRowCount(len(gui.visible))
+	gui.setStatus(fmt.Sprintf("%d of %d incidents", len(gui.visible), len(gui.data.Incidents)))
+}
+
+// editCorrectedTime asks for the corrected open time of an incident
+func (gui *guiState) editCorrectedTime(incident sla.Incident) {
+	dialog := ui.CreateEditDialog("Corrected time "+incident.ID, "Open time, e.g. 3h30m", incident.CorrectedTime)
+	dialog.OnClose(func() {
+		if dialog.Result() != ui.DialogButton1 {
+			return
+		}
+		value := strings.TrimSpace(dialog.EditResult())
+		if value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				gui.setStatus(fmt.Sprintf("Invalid corrected time %s", value))
+				return
+			}
+		}
+		correction := gui.correctionFor(incident)
+		correction.CorrectedTime = value
+		gui.askReason(correction)
+	})
+}
+
+// toggleExclude excludes an incident from the calculations or includes it again
+func (gui *guiState) toggleExclude(incident sla.Incident) {
+	correction := gui.correctionFor(incident)
+	correction.Exclude = !correction.Exclude
+	gui.askReason(correction)
+}
+
+// correctionFor returns the adjustment the incident has now, by the reviewer of the gui
+func (gui *guiState) correctionFor(incident sla.Incident) Correction {
+	return Correction{ID: incident.ID, Exclude: incident.Exclude, CorrectedTime: incident.CorrectedTime,
+		Reason: incident.Reason, Reviewer: gui.reviewer, Date: time.Now().Format(correctionDateFormat)}
+}
+
+// askReason asks why the incident is changed and saves the correction, a change without a reason is dropped
+func (gui *guiState) askReason(correction Correction) {
+	dialog := ui.CreateEditDialog("Reason "+correction.ID, "Why is the incident changed", correction.Reason)
+	dialog.OnClose(func() {
+		if dialog.Result() != ui.DialogButton1 {
+			return
+		}
+		correction.Reason = strings.TrimSpace(dialog.EditResult())
+		if correction.Reason == "" {
+			gui.setStatus("A reason is required, " + correction.ID + " is not changed")
+			return
+		}
+		gui.corrections.set(correction)
+		if err := writeCorrections(flagVars.correctionsFilename, gui.corrections); err != nil {
+			gui.setStatus(fmt.Sprintf("Error saving %s: %v", flagVars.correctionsFilename, err))
+			return
+		}
+		gui.refresh()
+	})
+}
+
+// generateReport writes the report with the adjustments, the workbook can be used as reference next time
+func (gui *guiState) generateReport() {
+	filenames, err := generateReport(&gui.data, flagVars.outputFilename, config.OutputDirectory, outputFormat("xlsx"))
+	if err != nil {
+		gui.setStatus(fmt.Sprintf("Error creating report: %v", err))
+		return
+	}
+	gui.setStatus("Wrote " + strings.Join(filenames, ", "))
+}
+
+func (gui *guiState) setStatus(text string) {
+	gui.statusLabel.SetTitle(text)
+	ui.PutEvent(ui.Event{Type: ui.EventRedraw})
+}
+
+func (gui *guiState) overviewRows() []guiOverviewRow {
+	var rows []guiOverviewRow
+	for _, area := range gui.data.Areas {
+		for _, priority := range area.Priorities {
+			rows = append(rows, guiOverviewRow{area: area.Name, priority: priority})
+		}
+	}
+	return rows
+}
+
+func (gui *guiState) availabilityRows() []guiAvailabilityRow {
+	var rows []guiAvailabilityRow
+	for _, area := range gui.data.Areas {
+		for _, service := range area.Availability {
+			rows = append(rows, guiAvailabilityRow{area: area.Name, service: service})
+		}
+	}
+	return rows
+}
+
+// drawOverviewCell shows the SLA performance with the number of incidents, red if below target
+func (gui *guiState) drawOverviewCell(info *ui.ColumnDrawInfo) {
+	rows := gui.overviewRows()
+	if info.Row >= len(rows) {
+		return
+	}
+	row := rows[info.Row]
+	switch info.Col {
+	case 0:
+		info.Text = row.area
+	case 1:
+		info.Text = row.priority.Priority
+	case 2:
+		info.Text = formatPercentage(row.priority.Target, 0)
+	default:
+		monthData := row.priority.Months[info.Col-3]
+		percentage, ok := monthData.Percentage()
+		if !ok {
+			info.Text = fmt.Sprintf("- (%d)", monthData.Total)
+			return
+		}
+		info.Text = fmt.Sprintf("%s (%d)", formatPercentage(percentage, 0), monthData.Total)
+		info.Fg = ui.ColorGreen
+		if percentage < row.priority.Target {
+			info.Fg = ui.ColorRed
+		}
+	}
+}
+
+// drawAvailabilityCell shows the availability of a service, red if below target
+func (gui *guiState) drawAvailabilityCell(info *ui.ColumnDrawInfo) {
+	rows := gui.availabilityRows()
+	if info.Row >= len(rows) {
+		return
+	}
+	row := rows[info.Row]
+	switch info.Col {
+	case 0:
+		info.Text = row.area
+	case 1:
+		info.Text = row.service.Service
+	case 2:
+		info.Text = formatPercentage(row.service.Target, 2)
+	default:
+		value := row.service.Months[info.Col-3]
+		info.Text = formatPercentage(value, 2)
+		info.Fg = ui.ColorGreen
+		if value < row.service.Target {
+			info.Fg = ui.ColorRed
+		}
+	}
+}
+
+func (gui *guiState) drawIncidentCell(info *ui.ColumnDrawInfo) {
+	if info.Row >= len(gui.visible) {
+		return
+	}
+	incident := gui.visible[info.Row]
+	switch info.Col {
+	case 0:
+		info.Text = incident.ID
+	case 1:
+		info.Text = incident.CreatedAt.Format("2006-01-02 15:04")
+	case 2:
+		info.Text = sla.PriorityNames[incident.Priority]
+	case 3:
+		info.Text = incident.BusinessArea
+	case 4:
+		info.Text = incident.Service
+	case 5:
+		if !incident.SolvedAt.IsZero() {
+			info.Text = fmt.Sprintf("%dm", incident.OpenTime)
+		}
+	case 6:
+		info.Text = incident.CorrectedTime
+	case 7:
+		if incident.Exclude {
+			info.Text = "Yes"
+		}
+	case 8:
+		if incident.SLAReady {
+			info.Text = "No"
+			info.Fg = ui.ColorRed
+			if incident.SLAMet {
+				info.Text = "Yes"
+				info.Fg = ui.ColorGreen
+			}
+		}
+	case 9:
+		info.Text = incident.Description
+	}
 }
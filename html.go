@@ -0,0 +1,485 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// htmlCell is a single cell of a table in the HTML report
+// Sort is used for client-side sorting when the displayed value does not sort well (dates, percentages)
+type htmlCell struct {
+	Value string
+	Sort  string
+	Class string
+	Link  string
+}
+
+// htmlTable is a table in the HTML report
+type htmlTable struct {
+	ID     string
+	Title  string
+	Header []string
+	Rows   [][]htmlCell
+}
+
+// htmlOverview contains the tables and charts for one (business) area
+type htmlOverview struct {
+	Title            string
+	Tables           []htmlTable
+	TotalChart       template.HTML
+	PerformanceChart template.HTML
+}
+
+// htmlReport is passed to the template to render the complete report
+type htmlReport struct {
+	Title          string
+	Warnings       []string
+	Overviews      []htmlOverview
+	Availability   []htmlTable
+	ProdCategories htmlTable
+	Incidents      htmlTable
+	Backlog        []htmlTable
+	BacklogChart   template.HTML
+}
+
+// htmlRenderer writes the report as a single HTML page, it implements Renderer
+type htmlRenderer struct{}
+
+// Render writes a self-contained HTML file with the report
+// all styling, charts and scripts are inline so the file can be mailed or opened on a phone
+func (htmlRenderer) Render(data *sla.ReportData, filename string) error {
+	report := htmlReport{
+		Title:    fmt.Sprintf("%s %s %d", data.Country, sla.MonthNames[data.Month], data.Year),
+		Warnings: data.Warnings,
+	}
+
+	months := data.MonthNames()
+	for _, area := range data.Areas {
+		report.Overviews = append(report.Overviews, newHTMLOverview(area, months))
+		if len(area.Availability) > 0 {
+			report.Availability = append(report.Availability, newHTMLAvailabilityTable(area, months))
+		}
+	}
+	report.ProdCategories = newHTMLProdCategoriesTable(data.ProdCategories)
+	report.Incidents = newHTMLIncidentsTable(data.Incidents)
+	report.Backlog = newHTMLBacklogTables(data.Backlog)
+	if len(data.Backlog.Trend) == len(months) {
+		trend := chartSeries{Name: "Open"}
+		for _, open := range data.Backlog.Trend {
+			trend.Values = append(trend.Values, float64(open))
+			trend.Valid = append(trend.Valid, true)
+		}
+		report.BacklogChart = svgLineChart("Backlog", months, []chartSeries{trend}, false)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(file, report)
+}
+
+func newHTMLOverview(areaData sla.AreaData, months []string) htmlOverview {
+	area := areaData.Name
+	if area != "" {
+		area = " " + area
+	}
+	overview := htmlOverview{Title: "Overview" + area}
+
+	header := append([]string{"Priority", "Target"}, months...)
+	totalTable := htmlTable{Title: "Total Incidents" + area, Header: header}
+	slaMetTable := htmlTable{Title: "SLA Met Incidents" + area, Header: header}
+	performanceTable := htmlTable{Title: "SLA Performance" + area, Header: header}
+
+	for _, priorityData := range areaData.Priorities {
+		totalRow := []htmlCell{{Value: priorityData.Priority}, {}}
+		slaMetRow := []htmlCell{{Value: priorityData.Priority}, {}}
+		performanceRow := []htmlCell{{Value: priorityData.Priority}, {Value: formatPercentage(priorityData.Target, 0)}}
+
+		for _, monthData := range priorityData.Months {
+			totalRow = append(totalRow, htmlCell{Value: strconv.Itoa(monthData.Total)})
+			slaMetRow = append(slaMetRow, htmlCell{Value: strconv.Itoa(monthData.SLAMet)})
+
+			cell := htmlCell{}
+			if percentage, ok := monthData.Percentage(); ok {
+				cell = newHTMLPercentageCell(percentage, 0, priorityData.Target)
+			}
+			performanceRow = append(performanceRow, cell)
+		}
+
+		totalTable.Rows = append(totalTable.Rows, totalRow)
+		slaMetTable.Rows = append(slaMetTable.Rows, slaMetRow)
+		performanceTable.Rows = append(performanceTable.Rows, performanceRow)
+	}
+
+	totalSeries, performanceSeries := newChartSeries(areaData)
+	overview.Tables = []htmlTable{totalTable, slaMetTable, performanceTable}
+	overview.TotalChart = svgLineChart("Total Incidents"+area, months, totalSeries, false)
+	overview.PerformanceChart = svgLineChart("SLA Performance"+area, months, performanceSeries, true)
+	return overview
+}
+
+func newHTMLAvailabilityTable(areaData sla.AreaData, months []string) htmlTable {
+	table := htmlTable{
+		Title:  "IT Service Availability",
+		Header: append([]string{"Service", "Target"}, months...),
+	}
+	for _, service := range areaData.Availability {
+		row := []htmlCell{{Value: service.Service}, {Value: formatPercentage(service.Target, 2)}}
+		for _, value := range service.Months {
+			row = append(row, newHTMLPercentageCell(value, 2, service.Target))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+func newHTMLProdCategoriesTable(categories []sla.ProdCategoryData) htmlTable {
+	table := htmlTable{
+		ID:     "prodcat",
+		Title:  "Product Categories",
+		Header: []string{"Product Category", "Total", "Met SLA", "Critical", "High", "Medium", "Low"},
+	}
+
+	for _, category := range categories {
+		table.Rows = append(table.Rows, []htmlCell{
+			{Value: category.Name},
+			{Value: strconv.Itoa(category.Total)},
+			{Value: strconv.Itoa(category.SLAMet)},
+			{Value: strconv.Itoa(category.Critical)},
+			{Value: strconv.Itoa(category.High)},
+			{Value: strconv.Itoa(category.Medium)},
+			{Value: strconv.Itoa(category.Low)},
+		})
+	}
+	return table
+}
+
+func newHTMLIncidentsTable(incidents sla.Incidents) htmlTable {
+	table := htmlTable{
+		ID:    "incidents",
+		Title: "Incidents",
+		Header: []string{"ID", "Created", "Solved", "Time Open", "Corrected Open", "Exclude", "Priority",
+			"Product Category Tier 1", "Product Category Tier 2", "Service", "Service CI", "Business Area",
+			"SLA Met", "Description", "Resolution", "Reason", "Reviewer", "Rule"},
+	}
+
+	const timeFormat = "2006-01-02 15:04"
+	for _, incident := range incidents {
+		solved := htmlCell{}
+		if incident.SLAReady {
+			solved = htmlCell{Value: incident.SolvedAt.Format(timeFormat)}
+		}
+		slaMet := htmlCell{Value: "No", Class: "red"}
+		if incident.SLAMet {
+			slaMet = htmlCell{Value: "Yes", Class: "green"}
+		}
+		table.Rows = append(table.Rows, []htmlCell{
+			{Value: incident.ID, Link: sla.IncidentURL(incident.ID)},
+			{Value: incident.CreatedAt.Format(timeFormat)},
+			solved,
+			{Value: strconv.Itoa(incident.OpenTime)},
+			{Value: incident.CorrectedTime},
+			{Value: strconv.FormatBool(incident.Exclude)},
+			{Value: sla.PriorityNames[incident.Priority], Sort: strconv.Itoa(incident.Priority)},
+			{Value: incident.ProdCategory1},
+			{Value: incident.ProdCategory2},
+			{Value: incident.Service},
+			{Value: incident.ServiceCI},
+			{Value: incident.BusinessArea},
+			slaMet,
+			{Value: incident.Description},
+			{Value: incident.Resolution},
+			{Value: incident.Reason},
+			{Value: incident.Reviewer},
+			{Value: incident.Rule},
+		})
+	}
+	return table
+}
+
+// newHTMLBacklogTables returns the open incidents per priority and age and the list of open incidents,
+// the ones past their SLA due time are red
+func newHTMLBacklogTables(backlog sla.BacklogData) []htmlTable {
+	title := "Backlog at " + backlog.At.Format("2006-01-02 15:04")
+	ages := htmlTable{Title: title, Header: append(append([]string{"Priority"}, backlog.Ages...), "Total", "Overdue")}
+	for _, priorityData := range backlog.Priorities {
+		row := []htmlCell{{Value: priorityData.Priority}}
+		for _, count := range priorityData.Ages {
+			row = append(row, htmlCell{Value: strconv.Itoa(count)})
+		}
+		overdue := htmlCell{Value: strconv.Itoa(priorityData.Overdue)}
+		if priorityData.Overdue > 0 {
+			overdue.Class = "red"
+		}
+		ages.Rows = append(ages.Rows, append(row, htmlCell{Value: strconv.Itoa(priorityData.Total)}, overdue))
+	}
+
+	list := htmlTable{
+		ID:     "backlog",
+		Title:  "Open Incidents",
+		Header: []string{"ID", "Priority", "Created", "Due", "Age", "Overdue", "Service", "Description"},
+	}
+	const timeFormat = "2006-01-02 15:04"
+	for _, incident := range backlog.Incidents {
+		overdue := htmlCell{Value: "No"}
+		if incident.Overdue {
+			overdue = htmlCell{Value: "Yes", Class: "red"}
+		}
+		list.Rows = append(list.Rows, []htmlCell{
+			{Value: incident.ID, Link: sla.IncidentURL(incident.ID)},
+			{Value: sla.PriorityNames[incident.Priority], Sort: strconv.Itoa(incident.Priority)},
+			{Value: incident.CreatedAt.Format(timeFormat)},
+			{Value: incident.Due.Format(timeFormat), Class: overdue.Class},
+			{Value: incident.Bucket, Sort: strconv.FormatInt(int64(incident.Age.Minutes()), 10)},
+			overdue,
+			{Value: incident.Service},
+			{Value: incident.Description},
+		})
+	}
+	return []htmlTable{ages, list}
+}
+
+// newHTMLPercentageCell returns a cell coloured green or red depending on the target
+func newHTMLPercentageCell(value float64, decimals int, target float64) htmlCell {
+	cell := htmlCell{
+		Value: formatPercentage(value, decimals),
+		Sort:  strconv.FormatFloat(value, 'f', 6, 64),
+		Class: "green",
+	}
+	if value < target {
+		cell.Class = "red"
+	}
+	return cell
+}
+
+func formatPercentage(value float64, decimals int) string {
+	return strconv.FormatFloat(value*100, 'f', decimals, 64) + "%"
+}
+
+// svgLineChart draws the series as an inline SVG line chart
+// if percent is true, the y axis runs from 0 to 100%, otherwise from 0 to the maximum value
+func svgLineChart(title string, labels []string, series []chartSeries, percent bool) template.HTML {
+	const steps = 5
+
+	// determine the scale of the y axis
+	maxValue := 1.0
+	if !percent {
+		for _, s := range series {
+			for _, value := range s.Values {
+				maxValue = math.Max(maxValue, value)
+			}
+		}
+		maxValue = math.Ceil(maxValue/steps) * steps
+	}
+	return svgLineChartRange(title, labels, series, percent, 0, maxValue)
+}
+
+// svgLineChartRange draws the series as an inline SVG line chart with the y axis from minValue to maxValue
+func svgLineChartRange(title string, labels []string, series []chartSeries, percent bool,
+	minValue float64, maxValue float64) template.HTML {
+
+	const (
+		width     = 480.0
+		height    = 280.0
+		left      = 44.0
+		right     = 12.0
+		top       = 30.0
+		bottom    = 64.0
+		steps     = 5
+		legendRow = 5
+	)
+	plotWidth := width - left - right
+	plotHeight := height - top - bottom
+
+	// show decimals on the y axis if the range is small
+	decimals := 0
+	if percent && maxValue-minValue < 0.05 {
+		decimals = 1
+	}
+
+	x := func(index int) float64 {
+		if len(labels) < 2 {
+			return left + plotWidth/2
+		}
+		return left + plotWidth*float64(index)/float64(len(labels)-1)
+	}
+	y := func(value float64) float64 {
+		return top + plotHeight - plotHeight*(value-minValue)/(maxValue-minValue)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="chart" viewBox="0 0 %.0f %.0f" xmlns="http://www.w3.org/2000/svg" role="img">`, width, height)
+	fmt.Fprintf(&b, `<text x="%.1f" y="18" text-anchor="middle" class="title">%s</text>`, width/2, template.HTMLEscapeString(title))
+
+	// grid lines and labels of the y axis
+	for step := 0; step <= steps; step++ {
+		value := minValue + (maxValue-minValue)*float64(step)/steps
+		label := strconv.FormatFloat(value, 'f', 0, 64)
+		if percent {
+			label = formatPercentage(value, decimals)
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, left, y(value), width-right, y(value))
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" class="axis">%s</text>`, left-4, y(value)+4, label)
+	}
+
+	// labels of the x axis
+	for index, label := range labels {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" class="axis">%s</text>`,
+			x(index), top+plotHeight+16, template.HTMLEscapeString(label))
+	}
+
+	// the lines, split up where values are missing
+	columns := len(series)
+	if columns > legendRow {
+		columns = legendRow
+	}
+	for seriesIdx, s := range series {
+		color := chartColors[seriesIdx%len(chartColors)]
+		var points []string
+		flush := func() {
+			if len(points) > 1 {
+				fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, color, strings.Join(points, " "))
+			}
+			points = nil
+		}
+		for index, value := range s.Values {
+			if !s.Valid[index] {
+				flush()
+				continue
+			}
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(index), y(value)))
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, x(index), y(value), color)
+		}
+		flush()
+
+		// legend at the bottom, in rows of at most 5
+		legendX := left + plotWidth*float64(seriesIdx%legendRow)/float64(columns)
+		legendY := height - 30 + 16*float64(seriesIdx/legendRow)
+		if len(series) <= legendRow {
+			legendY = height - 16
+		}
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="10" height="10" fill="%s"/>`, legendX, legendY-9, color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="axis">%s</text>`, legendX+14, legendY, template.HTMLEscapeString(s.Name))
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// htmlStyle is the inline stylesheet of the HTML report and the dashboard
+const htmlStyle = `<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 1em; color: #222; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.2em; margin-top: 1.5em; border-bottom: 1px solid #ccc; }
+h3 { font-size: 1em; }
+.scroll { overflow-x: auto; }
+table { border-collapse: collapse; margin-bottom: 1em; font-size: 0.85em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; }
+th { background: #eee; }
+table.sortable th { cursor: pointer; }
+td.green { background: #00FF00; text-align: center; }
+td.red { background: #FF0000; color: #FFFFFF; text-align: center; }
+td.red a { color: #FFFFFF; }
+.charts { display: flex; flex-wrap: wrap; gap: 1em; }
+svg.chart { width: 100%; max-width: 480px; height: auto; }
+svg .title { font-size: 14px; font-weight: bold; }
+svg .axis { font-size: 10px; fill: #444; }
+svg .grid { stroke: #ddd; stroke-width: 1; }
+input.filter { margin-bottom: 0.5em; padding: 0.25em; width: 100%; max-width: 24em; }
+</style>
+`
+
+// htmlTableTemplate defines the "table" template rendering an htmlTable
+const htmlTableTemplate = `{{define "table"}}
+<div class="scroll">
+<table{{if .ID}} id="{{.ID}}" class="sortable"{{end}}>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td{{if .Class}} class="{{.Class}}"{{end}}{{if .Sort}} data-sort="{{.Sort}}"{{end}}>{{if .Link}}<a href="{{.Link}}">{{.Value}}</a>{{else}}{{.Value}}{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</div>
+{{end}}`
+
+// htmlScript makes the tables with an ID sortable and the filter inputs work
+const htmlScript = `<script>
+(function () {
+	function cellValue(row, index) {
+		var cell = row.cells[index];
+		return cell.hasAttribute("data-sort") ? cell.getAttribute("data-sort") : cell.textContent;
+	}
+	document.querySelectorAll("table.sortable").forEach(function (table) {
+		table.querySelectorAll("th").forEach(function (th, index) {
+			var ascending = true;
+			th.addEventListener("click", function () {
+				var body = table.tBodies[0];
+				var rows = Array.prototype.slice.call(body.rows);
+				rows.sort(function (a, b) {
+					var x = cellValue(a, index), y = cellValue(b, index);
+					var result = (x !== "" && y !== "" && !isNaN(x) && !isNaN(y)) ? x - y : x.localeCompare(y);
+					return ascending ? result : -result;
+				});
+				ascending = !ascending;
+				rows.forEach(function (row) { body.appendChild(row); });
+			});
+		});
+	});
+	document.querySelectorAll("input.filter").forEach(function (input) {
+		var table = document.getElementById(input.getAttribute("data-table"));
+		input.addEventListener("input", function () {
+			var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+			Array.prototype.forEach.call(table.tBodies[0].rows, function (row) {
+				var text = row.textContent.toLowerCase();
+				row.style.display = terms.every(function (term) { return text.indexOf(term) !== -1; }) ? "" : "none";
+			});
+		});
+	});
+})();
+</script>
+`
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Report {{.Title}}</title>
+` + htmlStyle + `</head>
+<body>
+<h1>Report {{.Title}}</h1>
+{{if .Warnings}}<h2>Warnings</h2>
+<ul>{{range .Warnings}}<li>{{.}}</li>{{end}}</ul>
+{{end}}` + htmlTableTemplate + `
+{{range .Overviews}}
+<h2>{{.Title}}</h2>
+<div class="charts">{{.TotalChart}}{{.PerformanceChart}}</div>
+{{range .Tables}}<h3>{{.Title}}</h3>{{template "table" .}}{{end}}
+{{end}}
+{{range .Availability}}
+<h2>{{.Title}}</h2>
+{{template "table" .}}
+{{end}}
+<h2>{{.ProdCategories.Title}}</h2>
+{{template "table" .ProdCategories}}
+<h2>{{.Incidents.Title}}</h2>
+<input class="filter" type="search" placeholder="Filter incidents" data-table="incidents">
+{{template "table" .Incidents}}
+<h2>Backlog</h2>
+<div class="charts">{{.BacklogChart}}</div>
+{{range .Backlog}}<h3>{{.Title}}</h3>{{template "table" .}}{{end}}
+` + htmlScript + `</body>
+</html>
+`
@@ -1,37 +1,181 @@
 package main
 
-import "fmt"
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
 
-func listCountries(incidents []Incident) {
-	countries := make(map[string]int)
-	for _, incident := range incidents {
-		countries[incident.Country] = 1
-	}
+	"github.com/ronaldlens/goreport/sla"
+)
 
-	for country := range countries {
-		fmt.Println(country)
-	}
+// listNoun is what the list command can list, with the incident field it counts on
+type listNoun struct {
+	Title string
+	Key   func(incident *sla.Incident) string
+	Order []string // the order of the names when sorted by name, alphabetical if nil
+}
+
+var listNouns = map[string]listNoun{
+	"countries":      {Title: "Country", Key: func(incident *sla.Incident) string { return incident.Country }},
+	"prodcategories": {Title: "Product category", Key: func(incident *sla.Incident) string { return incident.ProdCategory2 }},
+	"services":       {Title: "Service", Key: func(incident *sla.Incident) string { return incident.Service }},
+	"cis":            {Title: "CI", Key: func(incident *sla.Incident) string { return incident.ServiceCI }},
+	"businessareas":  {Title: "Business area", Key: func(incident *sla.Incident) string { return incident.BusinessArea }},
+	"priorities": {Title: "Priority", Order: sla.PriorityNames,
+		Key: func(incident *sla.Incident) string { return sla.PriorityNames[incident.Priority] }},
+}
+
+// listNounNames are the nouns of the list command in the order of the usage
+var listNounNames = []string{"countries", "prodcategories", "services", "cis", "businessareas", "priorities"}
 
+// listRow is a name with its number of incidents, Counts holds the numbers per column of the table
+type listRow struct {
+	Name      string         `json:"name"`
+	Incidents int            `json:"incidents"`
+	Counts    map[string]int `json:"counts,omitempty"`
 }
 
-func listProductCategories(incidents []Incident) {
-	categories := make(map[string]int)
-	for _, incident := range incidents {
-		categories[incident.ProdCategory2] = 1
+// listTable is the outcome of a list command, Columns are the priorities or months with -per
+type listTable struct {
+	Title   string
+	Columns []string
+	Rows    []listRow
+}
+
+// buildList counts the incidents per name of the noun, optionally per priority or month,
+// and sorts the rows by name or by descending count
+func buildList(incidents sla.Incidents, noun listNoun, per string, sortBy string) (listTable, error) {
+	var column func(incident *sla.Incident) string
+	table := listTable{Title: noun.Title}
+	switch per {
+	case "":
+	case "priority":
+		column = func(incident *sla.Incident) string { return sla.PriorityNames[incident.Priority] }
+		table.Columns = append(table.Columns, sla.PriorityNames...)
+	case "month":
+		column = func(incident *sla.Incident) string { return incident.CreatedAt.Format("2006-01") }
+	default:
+		return table, &UsageError{Message: fmt.Sprintf("invalid -per %s, use priority or month", per)}
+	}
+
+	rows := make(map[string]*listRow)
+	months := make(map[string]bool)
+	for index := range incidents {
+		incident := &incidents[index]
+		name := noun.Key(incident)
+		row, found := rows[name]
+		if !found {
+			row = &listRow{Name: name}
+			if column != nil {
+				row.Counts = make(map[string]int)
+			}
+			rows[name] = row
+		}
+		row.Incidents++
+		if column != nil {
+			row.Counts[column(incident)]++
+		}
+		if per == "month" {
+			months[column(incident)] = true
+		}
+	}
+	for month := range months {
+		table.Columns = append(table.Columns, month)
+	}
+	if per == "month" {
+		sort.Strings(table.Columns)
+	}
+
+	for _, row := range rows {
+		table.Rows = append(table.Rows, *row)
+	}
+	order := make(map[string]int)
+	for index, name := range noun.Order {
+		order[name] = index
+	}
+	byName := func(i, j int) bool {
+		if noun.Order != nil {
+			return order[table.Rows[i].Name] < order[table.Rows[j].Name]
+		}
+		return table.Rows[i].Name < table.Rows[j].Name
+	}
+	switch sortBy {
+	case "name":
+		sort.Slice(table.Rows, byName)
+	case "count":
+		sort.Slice(table.Rows, func(i, j int) bool {
+			if table.Rows[i].Incidents != table.Rows[j].Incidents {
+				return table.Rows[i].Incidents > table.Rows[j].Incidents
+			}
+			return byName(i, j)
+		})
+	default:
+		return table, &UsageError{Message: fmt.Sprintf("invalid -sort %s, use name or count", sortBy)}
 	}
+	return table, nil
+}
 
-	for category := range categories {
-		fmt.Println(category)
+// writeList writes the table in the format: table, csv or json
+func writeList(out io.Writer, table listTable, format string) error {
+	switch format {
+	case "table":
+		return writeListTable(out, table)
+	case "csv":
+		return writeListCSV(out, table)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		rows := table.Rows
+		if rows == nil {
+			rows = []listRow{}
+		}
+		return encoder.Encode(rows)
 	}
+	return &UsageError{Message: fmt.Sprintf("invalid -format %s for list, use table, csv or json", format)}
 }
 
-func listServices(incidents []Incident) {
-	services := make(map[string]int)
-	for _, incident := range incidents {
-		services[incident.Service] = 1
+// writeListTable aligns the columns, with the total number of incidents on the last row
+func writeListTable(out io.Writer, table listTable) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	total := listRow{Name: "Total", Counts: make(map[string]int)}
+
+	fmt.Fprintf(writer, "%s\tIncidents\t", table.Title)
+	for _, column := range table.Columns {
+		fmt.Fprintf(writer, "%s\t", column)
+	}
+	fmt.Fprintln(writer)
+	writeRow := func(row listRow) {
+		fmt.Fprintf(writer, "%s\t%d\t", row.Name, row.Incidents)
+		for _, column := range table.Columns {
+			fmt.Fprintf(writer, "%d\t", row.Counts[column])
+		}
+		fmt.Fprintln(writer)
 	}
+	for _, row := range table.Rows {
+		writeRow(row)
+		total.Incidents += row.Incidents
+		for column, count := range row.Counts {
+			total.Counts[column] += count
+		}
+	}
+	writeRow(total)
+	return writer.Flush()
+}
 
-	for category := range services {
-		fmt.Println(category)
+func writeListCSV(out io.Writer, table listTable) error {
+	writer := csv.NewWriter(out)
+	_ = writer.Write(append([]string{table.Title, "Incidents"}, table.Columns...))
+	for _, row := range table.Rows {
+		record := []string{row.Name, strconv.Itoa(row.Incidents)}
+		for _, column := range table.Columns {
+			record = append(record, strconv.Itoa(row.Counts[column]))
+		}
+		_ = writer.Write(record)
 	}
+	writer.Flush()
+	return writer.Error()
 }
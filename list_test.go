@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_buildList(t *testing.T) {
+	created := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	incidents := sla.Incidents{
+		{ID: "1", Country: "Sweden", Priority: sla.High, CreatedAt: created},
+		{ID: "2", Country: "Norway", Priority: sla.Critical, CreatedAt: created},
+		{ID: "3", Country: "Sweden", Priority: sla.Critical, CreatedAt: created.AddDate(0, -1, 0)},
+		{ID: "4", Country: "Denmark", Priority: sla.Low, CreatedAt: created},
+	}
+
+	table, err := buildList(incidents, listNouns["countries"], "", "count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []listRow{{Name: "Sweden", Incidents: 2}, {Name: "Denmark", Incidents: 1}, {Name: "Norway", Incidents: 1}}
+	if !reflect.DeepEqual(table.Rows, expected) {
+		t.Errorf("Expected %v, got %v", expected, table.Rows)
+	}
+
+	// priorities are sorted in their order instead of alphabetically
+	table, err = buildList(incidents, listNouns["priorities"], "month", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, row := range table.Rows {
+		names = append(names, row.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Critical", "High", "Low"}) {
+		t.Errorf("Expected the priorities in order, got %v", names)
+	}
+	if !reflect.DeepEqual(table.Columns, []string{"2019-09", "2019-10"}) {
+		t.Errorf("Expected the months as columns, got %v", table.Columns)
+	}
+	if table.Rows[0].Counts["2019-09"] != 1 || table.Rows[0].Counts["2019-10"] != 1 {
+		t.Errorf("Expected a Critical incident in both months, got %v", table.Rows[0].Counts)
+	}
+
+	if _, err := buildList(incidents, listNouns["countries"], "week", "name"); err == nil {
+		t.Errorf("Expected an error for -per week")
+	}
+	if _, err := buildList(incidents, listNouns["countries"], "", "size"); err == nil {
+		t.Errorf("Expected an error for -sort size")
+	}
+
+	var out bytes.Buffer
+	table, _ = buildList(incidents, listNouns["countries"], "priority", "name")
+	if err := writeList(&out, table, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if lines[0] != "Country,Incidents,Critical,High,Medium,Low" || lines[3] != "Sweden,2,1,1,0,0" {
+		t.Errorf("Unexpected csv output\n%s", out.String())
+	}
+	if err := writeList(&out, table, "xlsx"); err == nil {
+		t.Errorf("Expected an error for the xlsx format")
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// defaults for the subject and body templates if not set in the configuration
+const (
+	defaultMailSubject = "Incident report {{.Country}} {{.MonthName}} {{.Year}}"
+	defaultMailBody    = "Hello,\n\nAttached is the incident report of {{.Country}} for {{.MonthName}} {{.Year}}.\n\n{{.Summary}}"
+)
+
+// content types of the attachments, mime.TypeByExtension does not know all of them on every platform
+var attachmentContentTypes = map[string]string{
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pdf":  "application/pdf",
+	".html": "text/html; charset=utf-8",
+	".json": "application/json",
+	".csv":  "text/csv; charset=utf-8",
+}
+
+// mailTemplateData is passed to the subject and body templates
+type mailTemplateData struct {
+	Country   string
+	Month     int
+	MonthName string
+	Year      int
+	Summary   string
+	Files     []string
+}
+
+// buildMail creates the message with the Markdown summary in the body and the files as attachments
+func buildMail(smtpConfig SMTP, recipients []string, data *sla.ReportData, attachments []string) ([]byte, error) {
+	var summary strings.Builder
+	writeSummary(&summary, data)
+
+	templateData := mailTemplateData{
+		Country:   data.Country,
+		Month:     data.Month,
+		MonthName: sla.MonthNames[data.Month],
+		Year:      data.Year,
+		Summary:   summary.String(),
+	}
+	for _, attachment := range attachments {
+		templateData.Files = append(templateData.Files, filepath.Base(attachment))
+	}
+
+	subject, err := executeMailTemplate("subject", smtpConfig.Subject, defaultMailSubject, templateData)
+	if err != nil {
+		return nil, err
+	}
+	body, err := executeMailTemplate("body", smtpConfig.Body, defaultMailBody, templateData)
+	if err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	writer := multipart.NewWriter(&content)
+
+	// the body as plain text, Markdown reads fine as is
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(body))
+
+	for _, attachment := range attachments {
+		file, err := ioutil.ReadFile(attachment)
+		if err != nil {
+			return nil, err
+		}
+		contentType, found := attachmentContentTypes[filepath.Ext(attachment)]
+		if !found {
+			contentType = "application/octet-stream"
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(attachment)})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, file)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", smtpConfig.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+	message.Write(content.Bytes())
+	return message.Bytes(), nil
+}
+
+// sendMail delivers the message through the configured SMTP server
+// authentication is only used if a username is configured
+func sendMail(smtpConfig SMTP, recipients []string, message []byte) error {
+	port := smtpConfig.Port
+	if port == 0 {
+		port = 25
+	}
+	var auth smtp.Auth
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	}
+	address := smtpConfig.Host + ":" + strconv.Itoa(port)
+	return smtp.SendMail(address, auth, smtpConfig.From, recipients, message)
+}
+
+func executeMailTemplate(name string, text string, defaultText string, data mailTemplateData) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing mail %s template: %v", name, err)
+	}
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("executing mail %s template: %v", name, err)
+	}
+	return result.String(), nil
+}
+
+// writeBase64 writes the data base64 encoded in lines of 76 characters
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		_, _ = w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	_, _ = w.Write([]byte(encoded + "\r\n"))
+}
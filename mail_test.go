@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_buildMail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	attachment := filepath.Join(dir, "report-sweden-10-2019.pdf")
+	if err := ioutil.WriteFile(attachment, []byte("%PDF-1.3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := sla.BuildReportData(nil, nil, "Sweden", 10, 2019, false, sla.MinimumIncidents{})
+	smtpConfig := SMTP{From: "goreport@example.com", Subject: "Report {{.Country}} {{.MonthName}}"}
+	message, err := buildMail(smtpConfig, []string{"a@example.com", "b@example.com"}, &data, []string{attachment})
+	if err != nil {
+		t.Fatalf("buildMail returned %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(message))
+	if err != nil {
+		t.Fatalf("Cannot parse mail: %v", err)
+	}
+	if subject := parsed.Header.Get("Subject"); subject != "Report Sweden Oct" {
+		t.Errorf("Expected subject 'Report Sweden Oct', got '%s'", subject)
+	}
+	if to := parsed.Header.Get("To"); to != "a@example.com, b@example.com" {
+		t.Errorf("Expected both recipients, got '%s'", to)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []*multipart.Part
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		parts = append(parts, part)
+		if part.FileName() == "" {
+			encoded, _ := ioutil.ReadAll(part)
+			body, err := base64.StdEncoding.DecodeString(strings.Replace(string(encoded), "\r\n", "", -1))
+			if err != nil {
+				t.Fatalf("Cannot decode body: %v", err)
+			}
+			if !strings.Contains(string(body), "# Incident summary Sweden Oct 2019") {
+				t.Errorf("Expected the summary in the body, got '%s'", body)
+			}
+		}
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
+	}
+	if parts[1].FileName() != "report-sweden-10-2019.pdf" {
+		t.Errorf("Expected the pdf as attachment, got '%s'", parts[1].FileName())
+	}
+}
@@ -1,28 +1,55 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"os"
 	"time"
+
+	"github.com/ronaldlens/goreport/sla"
 )
 
 func main() {
 	// start time measurement and parse command line flags
 	start := time.Now()
 
-	processCommandLineArgs()
-
-	// load the incidents
-	incidents, err := ImportIncidents(flagVars.inputFilename)
+	err := run()
 	if err != nil {
-		log.Fatalf("Error importing %s: %v", flagVars.inputFilename, err)
+		log.Printf("Error: %v", err)
+		os.Exit(exitCode(err))
 	}
+
 	if flagVars.verbose {
-		log.Printf("Loaded a total of %d incidents from %s\n", len(incidents), flagVars.inputFilename)
+		log.Printf("Total running time: %s\n", time.Since(start))
+	}
+}
+
+// run parses the command line, loads the configuration and incidents the command needs and runs it
+func run() error {
+	cmd, err := parseCommandLine(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		return err
+	}
+	if cmd.noConfig {
+		return cmd.run(nil)
 	}
 
-	processCommandLineCommand(incidents)
+	err = processCommandLineArgs()
+	if err != nil {
+		return err
+	}
+	if cmd.noInput {
+		return cmd.run(nil)
+	}
 
+	// load the incidents
+	incidents, err := sla.ImportIncidents(flagVars.inputFilename)
+	if err != nil {
+		return &InputError{Filename: flagVars.inputFilename, Err: err}
+	}
 	if flagVars.verbose {
-		log.Printf("Total running time: %s\n", time.Since(start))
+		log.Printf("Loaded a total of %d incidents from %s\n", len(incidents), flagVars.inputFilename)
 	}
+
+	return cmd.run(incidents)
 }
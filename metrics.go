@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// metricsContentType is the content type of the Prometheus text format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricFamily is a gauge in the Prometheus text format with all its samples
+type metricFamily struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+// metricSample is a value of a gauge, labels contains pairs of label names and values
+type metricSample struct {
+	labels []string
+	value  float64
+}
+
+func (family *metricFamily) add(value float64, labels ...string) {
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+// collectMetrics returns the gauges for the report month of every report
+func collectMetrics(reports []sla.ReportData) []*metricFamily {
+	performance := &metricFamily{name: "goreport_sla_performance_ratio", help: "SLA performance of the report month, after carrying over months below the minimum number of incidents."}
+	target := &metricFamily{name: "goreport_sla_target_ratio", help: "SLA performance target."}
+	solved := &metricFamily{name: "goreport_incidents_solved", help: "Number of incidents created and solved in the report month."}
+	slaMet := &metricFamily{name: "goreport_incidents_sla_met", help: "Number of incidents created in the report month that met the SLA."}
+	open := &metricFamily{name: "goreport_incidents_open", help: "Number of incidents created in the 6 months of the report that are not solved."}
+	availability := &metricFamily{name: "goreport_service_availability_ratio", help: "Availability of the IT service in the report month."}
+	availabilityTarget := &metricFamily{name: "goreport_service_availability_target_ratio", help: "Availability target of the IT service."}
+	generated := &metricFamily{name: "goreport_report_generated_timestamp_seconds", help: "Time the numbers were computed."}
+
+	for _, data := range reports {
+		current := len(data.Months) - 1
+		for _, area := range data.Areas {
+			for priority, priorityData := range area.Priorities {
+				labels := []string{"country", data.Country, "area", area.Name, "priority", priorityData.Priority}
+				monthData := priorityData.Months[current]
+				if value, ok := monthData.Percentage(); ok {
+					performance.add(value, labels...)
+				}
+				target.add(priorityData.Target, labels...)
+				solved.add(float64(monthData.Total), labels...)
+				slaMet.add(float64(monthData.SLAMet), labels...)
+
+				openIncidents := data.Incidents.FilterByPriority(priority)
+				if area.Name != "" {
+					openIncidents = openIncidents.FilterByBusinessArea(area.Name)
+				}
+				count := 0
+				for _, incident := range openIncidents {
+					// excluded incidents are not SLA ready either, so check the solved time
+					if incident.SolvedAt.IsZero() && !incident.Exclude {
+						count++
+					}
+				}
+				open.add(float64(count), labels...)
+			}
+			for _, service := range area.Availability {
+				labels := []string{"country", data.Country, "area", area.Name, "service", service.Service}
+				availability.add(service.Months[current], labels...)
+				availabilityTarget.add(service.Target, labels...)
+			}
+		}
+		generated.add(float64(data.GeneratedAt.Unix()), "country", data.Country)
+	}
+	return []*metricFamily{performance, target, solved, slaMet, open, availability, availabilityTarget, generated}
+}
+
+// writeMetrics writes the gauges in the Prometheus text format
+func writeMetrics(w io.Writer, families []*metricFamily) {
+	for _, family := range families {
+		if len(family.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", family.name)
+		for _, sample := range family.samples {
+			var labels []string
+			for index := 0; index+1 < len(sample.labels); index += 2 {
+				labels = append(labels, fmt.Sprintf("%s=\"%s\"", sample.labels[index], escapeLabelValue(sample.labels[index+1])))
+			}
+			fmt.Fprintf(w, "%s{%s} %s\n", family.name, strings.Join(labels, ","), strconv.FormatFloat(sample.value, 'g', -1, 64))
+		}
+	}
+}
+
+// writeMetricsFile writes the metrics for the node exporter textfile collector
+// the file is written under a temporary name and renamed, so the collector never reads half a file
+func writeMetricsFile(filename string, families []*metricFamily) error {
+	file, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	writeMetrics(file, families)
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), filename)
+}
+
+// prepareAllReportData computes the report of every configured country
+func prepareAllReportData(incidents sla.Incidents, month int, year int) ([]sla.ReportData, error) {
+	var reports []sla.ReportData
+	for _, countryConfig := range config.Countries {
+		data, err := prepareReportData(incidents, countryConfig, month, year)
+		if err != nil {
+			return nil, fmt.Errorf("country %s: %w", countryConfig.Name, err)
+		}
+		reports = append(reports, data)
+	}
+	return reports, nil
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
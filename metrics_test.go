@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_writeMetrics(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := sla.Incidents{
+		{ID: "1", Priority: sla.Critical, CreatedAt: created, SLAReady: true, SLAMet: true},
+		{ID: "2", Priority: sla.Critical, CreatedAt: created, SLAReady: true, SLAMet: false},
+		{ID: "3", Priority: sla.High, CreatedAt: created, SLAReady: false},
+		{ID: "4", Priority: sla.High, CreatedAt: created, SolvedAt: created.Add(time.Hour), Exclude: true},
+		{ID: "5", Priority: sla.Medium, CreatedAt: created, SolvedAt: created.Add(time.Hour), Exclude: true},
+	}
+	data := sla.BuildReportData(incidents, nil, `Swe"den`, 10, 2019, false, sla.MinimumIncidents{})
+
+	var result strings.Builder
+	writeMetrics(&result, collectMetrics([]sla.ReportData{data}))
+	output := result.String()
+
+	expected := []string{
+		"# TYPE goreport_sla_performance_ratio gauge\n",
+		`goreport_sla_performance_ratio{country="Swe\"den",area="",priority="Critical"} 0.5`,
+		`goreport_incidents_solved{country="Swe\"den",area="",priority="Critical"} 2`,
+		`goreport_incidents_open{country="Swe\"den",area="",priority="High"} 1`,
+		`goreport_service_availability_ratio{country="Swe\"den",area="",service="CRM"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected %q in the metrics", line)
+		}
+	}
+	// an excluded incident that is solved is not open
+	if !strings.Contains(output, `goreport_incidents_open{country="Swe\"den",area="",priority="Medium"} 0`) {
+		t.Errorf("Expected no open Medium incidents")
+	}
+	// no performance without incidents
+	if strings.Contains(output, `goreport_sla_performance_ratio{country="Swe\"den",area="",priority="Low"}`) {
+		t.Errorf("Expected no performance for Low")
+	}
+}
+
+func Test_metricsMonth(t *testing.T) {
+	flagVars.month, flagVars.year = 9, 2019
+	monthOnCommandLine = false
+	if month, year := metricsMonth(); month != int(time.Now().Month()) || year != time.Now().Year() {
+		t.Errorf("Expected the current month without -month and -year, got %d %d", month, year)
+	}
+	monthOnCommandLine = true
+	if month, year := metricsMonth(); month != 9 || year != 2019 {
+		t.Errorf("Expected the month on the command line, got %d %d", month, year)
+	}
+	monthOnCommandLine = false
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// below the target but within the margin is shown as amber, further below as red
+const (
+	slaAmberMargin          = 0.05
+	availabilityAmberMargin = 0.005
+)
+
+// the number of product categories listed on the product categories page
+const pdfTopProdCategories = 25
+
+// RGB colours used in the PDF
+var (
+	pdfGreen  = [3]int{0x00, 0xB0, 0x50}
+	pdfAmber  = [3]int{0xFF, 0xC0, 0x00}
+	pdfRed    = [3]int{0xFF, 0x00, 0x00}
+	pdfHeader = [3]int{0xE7, 0xE6, 0xE6}
+)
+
+// pdfReport wraps the gofpdf document with the translator for UTF-8 strings
+type pdfReport struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+// pdfRenderer writes the report as a PDF document, it implements Renderer
+type pdfRenderer struct{}
+
+// Render writes the report as a paginated A4 landscape PDF
+// every page has a header with the country and month and a footer with the page number
+func (pdfRenderer) Render(data *sla.ReportData, filename string) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	report := pdfReport{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	title := fmt.Sprintf("%s - %s %d", data.Country, sla.MonthNames[data.Month], data.Year)
+	pdf.SetTitle(report.tr("Report "+title), false)
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 10, report.tr("Incident report "+title), "B", 1, "L", false, 0, "")
+		pdf.Ln(4)
+	})
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	months := data.MonthNames()
+	for _, area := range data.Areas {
+		report.addOverviewPage(area, months)
+		report.addChartsPage(area, months)
+		if len(area.Availability) > 0 {
+			report.addAvailabilityPage(area, months)
+		}
+	}
+	report.addProdCategoriesPage(data.ProdCategories)
+	report.addAdjustmentsPage(data.Incidents)
+
+	return pdf.OutputFileAndClose(filename)
+}
+
+func (report *pdfReport) addOverviewPage(areaData sla.AreaData, months []string) {
+	pdf := report.pdf
+	area := areaData.Name
+	if area != "" {
+		area = " " + area
+	}
+	pdf.AddPage()
+
+	for _, table := range []struct {
+		title string
+		count func(sla.PriorityMonthData) int
+	}{
+		{"Total Incidents" + area, func(monthData sla.PriorityMonthData) int { return monthData.Total }},
+		{"SLA Met Incidents" + area, func(monthData sla.PriorityMonthData) int { return monthData.SLAMet }},
+	} {
+		report.heading(table.title)
+		report.tableHeader("Priority", months)
+		for _, priorityData := range areaData.Priorities {
+			report.cell(priorityData.Priority, 40, "L", nil)
+			report.cell("", 25, "C", nil)
+			for _, monthData := range priorityData.Months {
+				report.cell(strconv.Itoa(table.count(monthData)), 25, "C", nil)
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(4)
+	}
+
+	report.heading("SLA Performance" + area)
+	report.tableHeader("Priority", months)
+	for _, priorityData := range areaData.Priorities {
+		report.cell(priorityData.Priority, 40, "L", nil)
+		report.cell(formatPercentage(priorityData.Target, 0), 25, "C", nil)
+		for _, monthData := range priorityData.Months {
+			percentage, ok := monthData.Percentage()
+			if !ok {
+				report.cell("", 25, "C", nil)
+				continue
+			}
+			color := ragColor(percentage, priorityData.Target, slaAmberMargin)
+			report.cell(formatPercentage(percentage, 0), 25, "C", &color)
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func (report *pdfReport) addChartsPage(areaData sla.AreaData, months []string) {
+	pdf := report.pdf
+	area := areaData.Name
+	if area != "" {
+		area = " " + area
+	}
+	pdf.AddPage()
+
+	totalSeries, performanceSeries := newChartSeries(areaData)
+	_, top := pdf.GetXY()
+	report.lineChart(10, top, 135, 95, "Total Incidents"+area, months, totalSeries, false)
+	report.lineChart(152, top, 135, 95, "SLA Performance"+area, months, performanceSeries, true)
+}
+
+func (report *pdfReport) addAvailabilityPage(areaData sla.AreaData, months []string) {
+	pdf := report.pdf
+	pdf.AddPage()
+
+	report.heading("IT Service Availability")
+	report.tableHeader("Service", months)
+	for _, service := range areaData.Availability {
+		report.cell(service.Service, 40, "L", nil)
+		report.cell(formatPercentage(service.Target, 2), 25, "C", nil)
+		for _, value := range service.Months {
+			color := ragColor(value, service.Target, availabilityAmberMargin)
+			report.cell(formatPercentage(value, 2), 25, "C", &color)
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func (report *pdfReport) addProdCategoriesPage(categories []sla.ProdCategoryData) {
+	pdf := report.pdf
+	pdf.AddPage()
+
+	if len(categories) > pdfTopProdCategories {
+		categories = categories[:pdfTopProdCategories]
+	}
+
+	report.heading(fmt.Sprintf("Top %d Product Categories", len(categories)))
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(pdfHeader[0], pdfHeader[1], pdfHeader[2])
+	pdf.CellFormat(100, 6, "Product Category", "1", 0, "L", true, 0, "")
+	for _, header := range []string{"Total", "Met SLA", "Critical", "High", "Medium", "Low"} {
+		pdf.CellFormat(25, 6, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for _, category := range categories {
+		report.cell(category.Name, 100, "L", nil)
+		for _, value := range []int{category.Total, category.SLAMet, category.Critical, category.High, category.Medium, category.Low} {
+			report.cell(strconv.Itoa(value), 25, "C", nil)
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// addAdjustmentsPage lists the excluded and corrected incidents with the rule, reason and reviewer
+// of the adjustment, there is no page if no incident is adjusted
+func (report *pdfReport) addAdjustmentsPage(incidents sla.Incidents) {
+	var adjusted sla.Incidents
+	for _, incident := range incidents {
+		if incident.Exclude || incident.CorrectedTime != "" {
+			adjusted = append(adjusted, incident)
+		}
+	}
+	if len(adjusted) == 0 {
+		return
+	}
+	pdf := report.pdf
+	pdf.AddPage()
+
+	report.heading("Excluded and Corrected Incidents")
+	columns := []struct {
+		header string
+		width  float64
+	}{{"ID", 32}, {"Created", 30}, {"Priority", 20}, {"Exclude", 18}, {"Corrected", 20}, {"Rule", 40}, {"Reason", 85}, {"Reviewer", 30}}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(pdfHeader[0], pdfHeader[1], pdfHeader[2])
+	for _, column := range columns {
+		pdf.CellFormat(column.width, 6, column.header, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for _, incident := range adjusted {
+		exclude := ""
+		if incident.Exclude {
+			exclude = "Yes"
+		}
+		values := []string{incident.ID, incident.CreatedAt.Format("2006-01-02 15:04"), sla.PriorityNames[incident.Priority],
+			exclude, incident.CorrectedTime, incident.Rule, incident.Reason, incident.Reviewer}
+		for index, value := range values {
+			report.cell(value, columns[index].width, "L", nil)
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func (report *pdfReport) heading(title string) {
+	report.pdf.SetFont("Helvetica", "B", 11)
+	report.pdf.CellFormat(0, 8, report.tr(title), "", 1, "L", false, 0, "")
+}
+
+// tableHeader writes the header row of the overview tables, with the name, target and the months
+func (report *pdfReport) tableHeader(name string, months []string) {
+	pdf := report.pdf
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(pdfHeader[0], pdfHeader[1], pdfHeader[2])
+	pdf.CellFormat(40, 6, name, "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 6, "Target", "1", 0, "C", true, 0, "")
+	for _, month := range months {
+		pdf.CellFormat(25, 6, month, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// cell writes a table cell, if a colour is passed it is used as background
+func (report *pdfReport) cell(text string, width float64, align string, color *[3]int) {
+	pdf := report.pdf
+	pdf.SetFont("Helvetica", "", 9)
+	fill := color != nil
+	if fill {
+		pdf.SetFillColor(color[0], color[1], color[2])
+		if *color == pdfRed {
+			pdf.SetTextColor(0xFF, 0xFF, 0xFF)
+		}
+	}
+	pdf.CellFormat(width, 6, report.tr(text), "1", 0, align, fill, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// lineChart draws the series as a line chart in the box with the top left corner at x,y
+// if percent is true, the y axis runs from 0 to 100%, otherwise from 0 to the maximum value
+func (report *pdfReport) lineChart(x, y, width, height float64, title string, labels []string,
+	series []chartSeries, percent bool) {
+
+	const (
+		left   = 14.0
+		right  = 4.0
+		top    = 10.0
+		bottom = 18.0
+		steps  = 5
+	)
+	pdf := report.pdf
+	plotWidth := width - left - right
+	plotHeight := height - top - bottom
+
+	maxValue := 1.0
+	if !percent {
+		for _, s := range series {
+			for _, value := range s.Values {
+				maxValue = math.Max(maxValue, value)
+			}
+		}
+		maxValue = math.Ceil(maxValue/steps) * steps
+	}
+	pointX := func(index int) float64 {
+		if len(labels) < 2 {
+			return x + left + plotWidth/2
+		}
+		return x + left + plotWidth*float64(index)/float64(len(labels)-1)
+	}
+	pointY := func(value float64) float64 {
+		return y + top + plotHeight - plotHeight*value/maxValue
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetXY(x, y)
+	pdf.CellFormat(width, 6, report.tr(title), "", 0, "C", false, 0, "")
+
+	// grid lines and the labels of the axes
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.SetDrawColor(0xDD, 0xDD, 0xDD)
+	pdf.SetLineWidth(0.2)
+	for step := 0; step <= steps; step++ {
+		value := maxValue * float64(step) / steps
+		label := strconv.FormatFloat(value, 'f', 0, 64)
+		if percent {
+			label = formatPercentage(value, 0)
+		}
+		pdf.Line(x+left, pointY(value), x+width-right, pointY(value))
+		pdf.SetXY(x, pointY(value)-2)
+		pdf.CellFormat(left-1, 4, label, "", 0, "R", false, 0, "")
+	}
+	for index, label := range labels {
+		pdf.SetXY(pointX(index)-10, y+top+plotHeight+1)
+		pdf.CellFormat(20, 4, label, "", 0, "C", false, 0, "")
+	}
+
+	// the lines, with a gap where values are missing
+	pdf.SetLineWidth(0.6)
+	for seriesIdx, s := range series {
+		r, g, b := hexToRGB(chartColors[seriesIdx%len(chartColors)])
+		pdf.SetDrawColor(r, g, b)
+		pdf.SetFillColor(r, g, b)
+		for index, value := range s.Values {
+			if !s.Valid[index] {
+				continue
+			}
+			if index > 0 && s.Valid[index-1] {
+				pdf.Line(pointX(index-1), pointY(s.Values[index-1]), pointX(index), pointY(value))
+			}
+			pdf.Circle(pointX(index), pointY(value), 0.8, "F")
+		}
+
+		// legend at the bottom
+		legendX := x + left + plotWidth*float64(seriesIdx)/float64(len(series))
+		legendY := y + height - 8
+		pdf.Rect(legendX, legendY, 3, 3, "F")
+		pdf.SetXY(legendX+4, legendY-0.5)
+		pdf.CellFormat(25, 4, s.Name, "", 0, "L", false, 0, "")
+	}
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(0.2)
+	pdf.SetXY(x, y+height)
+}
+
+// ragColor returns green when the target is met, amber when within the margin below it and red otherwise
+func ragColor(value float64, target float64, margin float64) [3]int {
+	switch {
+	case value >= target:
+		return pdfGreen
+	case value >= target-margin:
+		return pdfAmber
+	default:
+		return pdfRed
+	}
+}
+
+// hexToRGB converts a colour in the #RRGGBB format to its components
+func hexToRGB(color string) (int, int, int) {
+	value, _ := strconv.ParseUint(color[1:], 16, 32)
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// ProcessReferenceFile processes the chnages made in an excel file.
+// If a reference Excel file is provided, go through the incident sheet of that workbook
+// and update our list oif incidents with ones that have a corrected outage time
+// or are marked to be excluded in the reference workbook.
+// With several workbooks, their corrections are merged according to the precedence.
+// The conflicts between the workbooks are returned to be shown in the report.
+func ProcessReferenceFile(incidents []sla.Incident, referenceFilenames []string,
+	precedence string) ([]sla.Incident, []referenceConflict, error) {
+
+	corrections, conflicts, err := readReferenceFiles(referenceFilenames, precedence)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// rows of incidents that are no longer in the input cannot be applied
+	ids := make(map[string]bool)
+	for _, incident := range incidents {
+		ids[incident.ID] = true
+	}
+	var unknown []string
+	for _, correction := range corrections {
+		if !ids[correction.ID] {
+			unknown = append(unknown, correction.ID)
+		}
+	}
+	if len(unknown) > 0 {
+		log.Printf("Warning: %d incidents in reference file %s are not in the input: %s",
+			len(unknown), strings.Join(referenceFilenames, ", "), strings.Join(unknown, ", "))
+	}
+
+	return applyCorrections(incidents, Corrections{Corrections: corrections}), conflicts, nil
+}
+
+// readReferenceFiles reads and merges the corrections of the reference workbooks,
+// it returns the incidents the workbooks disagree on and stops on them with precedence "error"
+func readReferenceFiles(referenceFilenames []string, precedence string) ([]Correction, []referenceConflict, error) {
+	var sets [][]Correction
+	for _, referenceFilename := range referenceFilenames {
+		corrections, err := readReferenceCorrections(referenceFilename)
+		if err != nil {
+			return nil, nil, err
+		}
+		sets = append(sets, corrections)
+	}
+
+	corrections, conflicts := mergeReferenceCorrections(referenceFilenames, sets, precedence)
+	for _, conflict := range conflicts {
+		log.Printf("Conflict for %s", conflict.describe())
+	}
+	if precedence == "error" && len(conflicts) > 0 {
+		return nil, nil, fmt.Errorf("%d %w", len(conflicts), ErrReferenceConflict)
+	}
+	return corrections, conflicts, nil
+}
+
+// readReferenceCorrections reads the Incidents sheet of a report workbook,
+// it returns a correction for every incident in the sheet
+func readReferenceCorrections(referenceFilename string) ([]Correction, error) {
+
+	// open the reference workbook
+	file, err := excelize.OpenFile(referenceFilename)
+	if err != nil {
+		return nil, &AdjustmentError{Filename: referenceFilename, Err: err}
+	}
+
+	// get all the rows in the sheet titled Incidents
+	rows, err := file.GetRows("Incidents")
+	if err != nil {
+		return nil, &AdjustmentError{Filename: referenceFilename, Err: fmt.Errorf("reading rows: %w", err)}
+	}
+	corrections, err := parseReferenceRows(rows)
+	var rowErr *ReferenceRowError
+	if errors.As(err, &rowErr) {
+		rowErr.Filename = referenceFilename
+		return nil, rowErr
+	}
+	if err != nil {
+		return nil, &AdjustmentError{Filename: referenceFilename, Err: err}
+	}
+	return corrections, nil
+}
+
+// parseReferenceRows finds the columns by their header in the first row, so they can be moved around.
+// The Reason, Reviewer and Rule columns are optional. Cells at the end of a row may be missing.
+// Exclusions made by a rule are left out, so they follow the rules of the current run.
+func parseReferenceRows(rows [][]string) ([]Correction, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no header row in the Incidents sheet")
+	}
+	columns := make(map[string]int)
+	for column, header := range rows[0] {
+		columns[strings.TrimSpace(header)] = column
+	}
+	for _, header := range []string{"ID", "Corrected Open", "Exclude"} {
+		if _, found := columns[header]; !found {
+			return nil, fmt.Errorf("no %s column in the Incidents sheet", header)
+		}
+	}
+	cell := func(row []string, header string) string {
+		column, found := columns[header]
+		if !found || column >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[column])
+	}
+
+	var corrections []Correction
+	// skip the first row, this is the header row
+	for index, row := range rows[1:] {
+		rowNumber := index + 2
+		correction := Correction{
+			ID:       cell(row, "ID"),
+			Reason:   cell(row, "Reason"),
+			Reviewer: cell(row, "Reviewer"),
+		}
+		if correction.ID == "" {
+			continue
+		}
+
+		// the corrected open time is a duration like 3h30m
+		if correctedTime := cell(row, "Corrected Open"); correctedTime != "" {
+			_, err := time.ParseDuration(correctedTime)
+			if err != nil {
+				return nil, &ReferenceRowError{Row: rowNumber, ID: correction.ID,
+					Err: fmt.Errorf("parsing corrected time '%s': %w", correctedTime, err)}
+			}
+			correction.CorrectedTime = correctedTime
+		}
+
+		var err error
+		correction.Exclude, err = parseReferenceBool(cell(row, "Exclude"))
+		if err != nil {
+			return nil, &ReferenceRowError{Row: rowNumber, ID: correction.ID, Err: fmt.Errorf("exclude: %w", err)}
+		}
+
+		// an exclusion written by a rule is not a decision of a reviewer, the rules decide again on every run
+		if correction.Exclude && correction.Reviewer == "" && cell(row, "Rule") != "" &&
+			strings.HasPrefix(correction.Reason, sla.RuleExclusionReason) {
+			correction.Exclude = false
+			correction.Reason = ""
+		}
+		corrections = append(corrections, correction)
+	}
+	return corrections, nil
+}
+
+// parseReferenceBool accepts the ways a yes or no can be entered in a workbook, empty is no
+func parseReferenceBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false", "no", "n":
+		return false, nil
+	case "1", "true", "yes", "y":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid value '%s', use TRUE/FALSE, yes/no or 1/0", value)
+}
+
+func findIncidentByID(incidents []sla.Incident, ID string) int {
+	for idx, incident := range incidents {
+		if incident.ID == ID {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ronaldlens/goreport/sla"
+	"github.com/ronaldlens/goreport/xlsx"
+)
+
+// Renderer writes the computed report data to a file in a specific output format
+type Renderer interface {
+	Render(data *sla.ReportData, filename string) error
+}
+
+// newRenderer returns the renderer for an output format
+func newRenderer(format string) (Renderer, error) {
+	switch format {
+	case "xlsx":
+		return &xlsx.Sheet{}, nil
+	case "html":
+		return htmlRenderer{}, nil
+	case "pdf":
+		return pdfRenderer{}, nil
+	case "json":
+		return jsonRenderer{}, nil
+	case "csv":
+		return csvRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown report format %s", format)
+}
+
+// chartSeries is a line in a chart, values that are not valid are drawn as a gap
+type chartSeries struct {
+	Name   string
+	Values []float64
+	Valid  []bool
+}
+
+// colors used for the series in the charts, the first 4 for the priorities
+var chartColors = []string{"#C00000", "#ED7D31", "#4472C4", "#70AD47",
+	"#7030A0", "#00B0F0", "#FFC000", "#808080", "#000000", "#996633"}
+
+// newChartSeries returns the series for the total incidents and the SLA performance charts of an area
+func newChartSeries(areaData sla.AreaData) ([]chartSeries, []chartSeries) {
+	var totalSeries, performanceSeries []chartSeries
+	for _, priorityData := range areaData.Priorities {
+		total := chartSeries{Name: priorityData.Priority}
+		performance := chartSeries{Name: priorityData.Priority}
+		for _, monthData := range priorityData.Months {
+			percentage, ok := monthData.Percentage()
+			total.Values = append(total.Values, float64(monthData.Total))
+			total.Valid = append(total.Valid, true)
+			performance.Values = append(performance.Values, percentage)
+			performance.Valid = append(performance.Valid, ok)
+		}
+		totalSeries = append(totalSeries, total)
+		performanceSeries = append(performanceSeries, performance)
+	}
+	return totalSeries, performanceSeries
+}
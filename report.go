@@ -1,54 +1,64 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"path/filepath"
-)
+	"strings"
 
-func runReport(incidents *Incidents, localIncidents *Incidents, country string, month int, year int, splitArea bool,
-	outputFilename string, minimumIncidents MinimumIncidents, verbose bool, outputDirectory string) {
+	"github.com/ronaldlens/goreport/sla"
+)
 
-	if outputFilename == "" {
-		outputFilename = getFilename(country, month, year)
+// runReport writes the report data in each of the comma separated formats
+// it returns the names of the files written
+func runReport(data *sla.ReportData, outputFilename string, outputDirectory string, format string, verbose bool) ([]string, error) {
+	filenames, err := generateReport(data, outputFilename, outputDirectory, format)
+	if err != nil {
+		return filenames, fmt.Errorf("creating report: %w", err)
 	}
+	if verbose {
+		for _, filename := range filenames {
+			log.Printf("Wrote output to %s", filename)
+		}
+	}
+	return filenames, nil
+}
 
-	var sheet Sheet
-	sheet.init()
-
-	var totalIncidents Incidents
-	if splitArea {
-		itIncidents := incidents.filterByBusinessArea("IT")
-		sheet.setupOverviewSheet("IT")
-		itIncidents = itIncidents.reportOnSixMonths(month, year, "IT", &sheet, minimumIncidents)
-		sheet.createCharts("IT")
-
-		networkIncidents := incidents.filterByBusinessArea("Network")
-		sheet.setupOverviewSheet("Network")
-		networkIncidents = networkIncidents.reportOnSixMonths(month, year, "Network", &sheet, minimumIncidents)
-		sheet.createCharts("Network")
+// generateReport renders the report in each of the formats and returns the files written
+func generateReport(data *sla.ReportData, outputFilename string, outputDirectory string, format string) ([]string, error) {
+	var filenames []string
 
-		totalIncidents = append(itIncidents, networkIncidents...)
+	// several formats can be requested at once, separated by commas
+	formats := strings.Split(format, ",")
+	for _, format := range formats {
+		renderer, err := newRenderer(format)
+		if err != nil {
+			return filenames, err
+		}
 
-	} else {
-		sheet.setupOverviewSheet("")
-		totalIncidents = incidents.reportOnSixMonths(month, year, "", &sheet, minimumIncidents)
+		filename := outputFilename
+		if filename == "" {
+			filename = getFilenameWithExtension(data.Country, data.Month, data.Year, format)
+		} else if len(formats) > 1 {
+			filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + "." + format
+		}
+		if outputDirectory != "" {
+			filename = filepath.Join(outputDirectory, filename)
+		}
 
-		sheet.createCharts("")
+		err = renderer.Render(data, filename)
+		if err != nil {
+			return filenames, &OutputError{Filename: filename, Err: fmt.Errorf("saving %s file: %w", format, err)}
+		}
+		filenames = append(filenames, filename)
 	}
+	return filenames, nil
+}
 
-	sheet.addProdCategoriesToSheet(totalIncidents)
-	sheet.addIncidentsToSheet(totalIncidents, "Incidents")
-	sheet.addIncidentsToSheet(localIncidents.getSixMonthsIncidents(month, year), "Local Incidents")
-	if outputDirectory != "" {
-		outputFilename = filepath.Join(outputDirectory, outputFilename)
-
-	}
-	err := sheet.SaveAs(outputFilename)
-	if err != nil {
-		log.Fatalf("Error saving excel file: %v", err)
-	}
-	if verbose {
-		log.Printf("Wrote output to %s", outputFilename)
-	}
+func getFilename(country string, month int, year int) string {
+	return getFilenameWithExtension(country, month, year, "xlsx")
+}
 
+func getFilenameWithExtension(country string, month int, year int, extension string) string {
+	return fmt.Sprintf("report-%s-%02d-%d.%s", strings.ToLower(country), month, year, extension)
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// reviewCandidates returns the incidents of the month to review: the Critical ones and the ones
+// that breached the SLA, leaving out the incidents that already have a correction
+func reviewCandidates(incidents sla.Incidents, corrections Corrections, month int, year int) sla.Incidents {
+	var candidates sla.Incidents
+	for _, incident := range incidents.FilterByMonthYear(month, year) {
+		if _, found := corrections.find(incident.ID); found {
+			continue
+		}
+		if incident.Priority == sla.Critical || (incident.SLAReady && !incident.SLAMet) {
+			candidates = append(candidates, incident)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority < candidates[j].Priority
+		}
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+	return candidates
+}
+
+// reviewIncidents asks for a decision on each incident and saves the corrections after every decision,
+// so quitting halfway keeps the decisions made so far
+func reviewIncidents(in io.Reader, out io.Writer, incidents sla.Incidents, corrections *Corrections, reviewer string,
+	save func(Corrections) error) error {
+
+	scanner := bufio.NewScanner(in)
+	ask := func(question string) (string, bool) {
+		fmt.Fprint(out, question)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	for index, incident := range incidents {
+		fmt.Fprintf(out, "\n[%d/%d] ", index+1, len(incidents))
+		writeReviewIncident(out, incident)
+
+	decision:
+		for {
+			answer, ok := ask("(e)xclude, (c)orrect open time, (s)kip, (q)uit? ")
+			if !ok {
+				return nil
+			}
+			correction := Correction{ID: incident.ID, Reviewer: reviewer, Date: time.Now().Format(correctionDateFormat)}
+			switch strings.ToLower(answer) {
+			case "e", "exclude":
+				correction.Exclude = true
+			case "c", "correct":
+				value, ok := ask("Corrected open time (e.g. 3h30m): ")
+				if !ok {
+					return nil
+				}
+				if _, err := time.ParseDuration(value); err != nil {
+					fmt.Fprintf(out, "Invalid duration %s\n", value)
+					continue
+				}
+				correction.CorrectedTime = value
+			case "s", "skip":
+			case "q", "quit":
+				return nil
+			default:
+				continue
+			}
+
+			for {
+				reason, ok := ask("Reason: ")
+				if !ok {
+					return nil
+				}
+				correction.Reason = reason
+				// every decision needs a reason, a skipped incident is only recorded for it
+				if reason != "" {
+					break
+				}
+			}
+
+			corrections.set(correction)
+			if err := save(*corrections); err != nil {
+				return err
+			}
+			break decision
+		}
+	}
+	fmt.Fprintf(out, "\nReviewed %d incidents\n", len(incidents))
+	return nil
+}
+
+// writeReviewIncident shows the details needed to decide on an incident
+func writeReviewIncident(out io.Writer, incident sla.Incident) {
+	const timeFormat = "2006-01-02 15:04"
+	solved := "not solved"
+	openTime := "-"
+	if !incident.SolvedAt.IsZero() {
+		solved = incident.SolvedAt.Format(timeFormat)
+		openTime = (time.Duration(incident.OpenTime) * time.Minute).String()
+	}
+	status := "SLA met"
+	if incident.Exclude {
+		status = "excluded"
+	} else if !incident.SLAReady {
+		status = "open"
+	} else if !incident.SLAMet {
+		status = "SLA breached"
+	}
+
+	fmt.Fprintf(out, "%s %s, %s\n", incident.ID, sla.PriorityNames[incident.Priority], status)
+	fmt.Fprintf(out, "Created:     %s   Solved: %s   Open: %s\n", incident.CreatedAt.Format(timeFormat), solved, openTime)
+	fmt.Fprintf(out, "Service:     %s (%s)   Area: %s\n", incident.Service, incident.ServiceCI, incident.BusinessArea)
+	fmt.Fprintf(out, "Category:    %s / %s\n", incident.ProdCategory1, incident.ProdCategory2)
+	fmt.Fprintf(out, "Link:        %s\n", sla.IncidentURL(incident.ID))
+	fmt.Fprintf(out, "Description: %s\n", incident.Description)
+	fmt.Fprintf(out, "Resolution:  %s\n", incident.Resolution)
+}
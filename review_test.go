@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+func Test_reviewIncidents(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := sla.Incidents{
+		{ID: "1", Priority: sla.High, CreatedAt: created, SLAReady: true, SLAMet: true},
+		{ID: "2", Priority: sla.High, CreatedAt: created, SLAReady: true, SLAMet: false},
+		{ID: "3", Priority: sla.Critical, CreatedAt: created, SLAReady: true, SLAMet: true},
+		{ID: "4", Priority: sla.Critical, CreatedAt: created.AddDate(0, -1, 0), SLAReady: true, SLAMet: false},
+		{ID: "5", Priority: sla.Critical, CreatedAt: created, SLAReady: true, SLAMet: false},
+		{ID: "6", Priority: sla.Low, CreatedAt: created, SLAReady: true, SLAMet: false},
+	}
+	corrections := Corrections{Corrections: []Correction{{ID: "5", Reason: "reviewed before"}}}
+
+	candidates := reviewCandidates(incidents, corrections, 10, 2019)
+	var ids []string
+	for _, incident := range candidates {
+		ids = append(ids, incident.ID)
+	}
+	if strings.Join(ids, ",") != "3,2,6" {
+		t.Fatalf("Expected incidents 3, 2 and 6 to review, got %v", ids)
+	}
+
+	// exclude 3, correct 2 after an invalid duration, each asked again for an empty reason
+	input := "e\nmaintenance\nc\nfoo\nc\n2h\n\nlate closure\ns\n\nduplicate\n"
+	saves := 0
+	err := reviewIncidents(strings.NewReader(input), ioutil.Discard, candidates, &corrections, "jane", func(Corrections) error {
+		saves++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saves != 3 {
+		t.Errorf("Expected 3 saves, got %d", saves)
+	}
+
+	expected := map[string]Correction{
+		"3": {ID: "3", Exclude: true, Reason: "maintenance", Reviewer: "jane"},
+		"2": {ID: "2", CorrectedTime: "2h", Reason: "late closure", Reviewer: "jane"},
+		"6": {ID: "6", Reason: "duplicate", Reviewer: "jane"},
+	}
+	for id, want := range expected {
+		got, _ := corrections.find(id)
+		if got.Date == "" {
+			t.Errorf("Expected correction %s to have a date", id)
+		}
+		got.Date = ""
+		if got != want {
+			t.Errorf("Expected correction %v, got %v", want, got)
+		}
+	}
+
+	incidents = applyCorrections(incidents, corrections)
+	if !incidents[2].Exclude || incidents[2].SLAReady {
+		t.Errorf("Expected incident 3 to be excluded")
+	}
+	if incidents[1].CorrectedOpenTime != 2*time.Hour {
+		t.Errorf("Expected incident 2 to have a corrected time of 2h, got %v", incidents[1].CorrectedOpenTime)
+	}
+}
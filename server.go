@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+	"github.com/ronaldlens/goreport/xlsx"
+)
+
+// apiIncident is the JSON representation of an incident in the REST API
+type apiIncident struct {
+	ID            string     `json:"id"`
+	Country       string     `json:"country"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	SolvedAt      *time.Time `json:"solvedAt"`
+	Priority      string     `json:"priority"`
+	Service       string     `json:"service"`
+	ServiceCI     string     `json:"serviceCI"`
+	BusinessArea  string     `json:"businessArea"`
+	ProdCategory1 string     `json:"prodCategory1"`
+	ProdCategory2 string     `json:"prodCategory2"`
+	Corporate     bool       `json:"corporate"`
+	OpenTime      int        `json:"openTime"`
+	CorrectedTime string     `json:"correctedTime,omitempty"`
+	Exclude       bool       `json:"exclude"`
+	Rule          string     `json:"rule,omitempty"`
+	SLAMet        bool       `json:"slaMet"`
+	Description   string     `json:"description"`
+	Resolution    string     `json:"resolution"`
+	URL           string     `json:"url"`
+}
+
+// apiCountry is the JSON representation of a country in the REST API
+type apiCountry struct {
+	Name       string `json:"name"`
+	Configured bool   `json:"configured"`
+	Incidents  int    `json:"incidents"`
+}
+
+// server holds the incidents loaded at startup, every request works on a copy.
+// The input file is imported again when it changed, if inputFilename is set.
+type server struct {
+	incidents     sla.Incidents
+	inputFilename string
+	loadedAt      time.Time // the modification time of the input file when it was imported
+	mutex         sync.Mutex
+}
+
+// runServer starts the HTTP server with the dashboard and the REST API, it only returns on error
+func runServer(incidents sla.Incidents, address string) error {
+	srv := &server{incidents: incidents, inputFilename: flagVars.inputFilename}
+	if info, err := os.Stat(srv.inputFilename); err == nil {
+		srv.loadedAt = info.ModTime()
+	}
+	mux := http.NewServeMux()
+	srv.registerAPI(mux)
+	srv.registerDashboard(mux)
+
+	if flagVars.verbose {
+		log.Printf("Listening on %s", address)
+	}
+	return http.ListenAndServe(address, logRequests(mux))
+}
+
+// getIncidents returns the incidents, after importing the input file again if it was modified since.
+// If the new file cannot be imported the incidents loaded before are kept.
+func (srv *server) getIncidents() sla.Incidents {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	if srv.inputFilename == "" {
+		return srv.incidents
+	}
+	info, err := os.Stat(srv.inputFilename)
+	if err != nil || !info.ModTime().After(srv.loadedAt) {
+		return srv.incidents
+	}
+	incidents, err := sla.ImportIncidents(srv.inputFilename)
+	if err != nil {
+		log.Printf("Warning: keeping the incidents loaded before, %s: %v", srv.inputFilename, err)
+		return srv.incidents
+	}
+	srv.incidents, srv.loadedAt = incidents, info.ModTime()
+	if flagVars.verbose {
+		log.Printf("Reloaded %d incidents from %s", len(incidents), srv.inputFilename)
+	}
+	return srv.incidents
+}
+
+func (srv *server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("/api/countries", srv.handleCountries)
+	mux.HandleFunc("/api/incidents", srv.handleIncidents)
+	mux.HandleFunc("/api/report", srv.handleReport)
+	mux.HandleFunc("/api/report.xlsx", srv.handleReportXLSX)
+	mux.HandleFunc("/metrics", srv.handleMetrics)
+}
+
+// handleCountries lists all countries in the incidents and whether they are configured
+func (srv *server) handleCountries(w http.ResponseWriter, r *http.Request) {
+	counts := make(map[string]int)
+	for _, incident := range srv.getIncidents() {
+		counts[incident.Country]++
+	}
+	for _, country := range config.Countries {
+		if _, found := counts[country.Name]; !found {
+			counts[country.Name] = 0
+		}
+	}
+
+	countries := []apiCountry{}
+	for name, count := range counts {
+		_, configured := findCountryInConfig(config, name)
+		countries = append(countries, apiCountry{Name: name, Configured: configured, Incidents: count})
+	}
+	sort.Slice(countries, func(i, j int) bool { return countries[i].Name < countries[j].Name })
+	writeJSON(w, http.StatusOK, countries)
+}
+
+// handleIncidents returns the incidents of a country, optionally filtered by
+// month and year or only the year, priority, service and whether the SLA was met
+func (srv *server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	countryConfig, month, year, err := parseReportQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	query := r.URL.Query()
+	incidents, _, err := prepareIncidents(srv.getIncidents(), countryConfig, month, year)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if query.Get("month") != "" {
+		incidents = incidents.FilterByMonthYear(month, year)
+	} else if query.Get("year") != "" {
+		incidents = incidents.FilterByYear(year)
+	}
+	if priority := query.Get("priority"); priority != "" {
+		id, err := sla.ParsePriority(priority)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		incidents = incidents.FilterByPriority(id)
+	}
+	if service := query.Get("service"); service != "" {
+		incidents = incidents.FilterByService(service)
+	}
+	if slaMet := query.Get("slamet"); slaMet != "" {
+		met, err := strconv.ParseBool(slaMet)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid value for slamet: %s", slaMet))
+			return
+		}
+		incidents = incidents.FilterBySLAMet(met)
+	}
+
+	result := []apiIncident{}
+	for _, incident := range incidents {
+		result = append(result, newAPIIncident(incident))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleReport returns the computed numbers of the report as JSON
+func (srv *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	countryConfig, month, year, err := parseReportQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	data, err := prepareReportData(srv.getIncidents(), countryConfig, month, year)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// handleReportXLSX generates the workbook and sends it as a download
+func (srv *server) handleReportXLSX(w http.ResponseWriter, r *http.Request) {
+	countryConfig, month, year, err := parseReportQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	data, err := prepareReportData(srv.getIncidents(), countryConfig, month, year)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the renderers write to a file, use a temporary directory
+	dir, err := ioutil.TempDir("", "goreport")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	filename := getFilename(data.Country, data.Month, data.Year)
+	var sheet xlsx.Sheet
+	if err := sheet.Render(&data, filepath.Join(dir, filename)); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachmentContentTypes[".xlsx"])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeFile(w, r, filepath.Join(dir, filename))
+}
+
+// handleMetrics returns the numbers of all configured countries for Prometheus,
+// for the current month at the time of the scrape unless -month and -year are given
+func (srv *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	month, year := metricsMonth()
+	reports, err := prepareAllReportData(srv.getIncidents(), month, year)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	writeMetrics(w, collectMetrics(reports))
+}
+
+// parseReportQuery gets the country, month and year from the query,
+// defaulting to the ones on the command line
+func parseReportQuery(r *http.Request) (Country, int, int, error) {
+	query := r.URL.Query()
+	countryName := query.Get("country")
+	if countryName == "" {
+		countryName = flagVars.country
+	}
+	countryConfig, found := findCountryInConfig(config, countryName)
+	if !found {
+		return Country{}, 0, 0, fmt.Errorf("country %s is not configured", countryName)
+	}
+
+	month, year := flagVars.month, flagVars.year
+	if value := query.Get("month"); value != "" {
+		var err error
+		month, err = strconv.Atoi(value)
+		if err != nil || month < 1 || month > 12 {
+			return Country{}, 0, 0, fmt.Errorf("invalid month: %s", value)
+		}
+	}
+	if value := query.Get("year"); value != "" {
+		var err error
+		year, err = strconv.Atoi(value)
+		if err != nil {
+			return Country{}, 0, 0, fmt.Errorf("invalid year: %s", value)
+		}
+	}
+	return countryConfig, month, year, nil
+}
+
+func newAPIIncident(incident sla.Incident) apiIncident {
+	result := apiIncident{
+		ID:            incident.ID,
+		Country:       incident.Country,
+		CreatedAt:     incident.CreatedAt,
+		Priority:      sla.PriorityNames[incident.Priority],
+		Service:       incident.Service,
+		ServiceCI:     incident.ServiceCI,
+		BusinessArea:  incident.BusinessArea,
+		ProdCategory1: incident.ProdCategory1,
+		ProdCategory2: incident.ProdCategory2,
+		Corporate:     incident.FlagCorp,
+		OpenTime:      incident.OpenTime,
+		CorrectedTime: incident.CorrectedTime,
+		Exclude:       incident.Exclude,
+		Rule:          incident.Rule,
+		SLAMet:        incident.SLAMet,
+		Description:   incident.Description,
+		Resolution:    incident.Resolution,
+		URL:           sla.IncidentURL(incident.ID),
+	}
+	if !incident.SolvedAt.IsZero() {
+		solvedAt := incident.SolvedAt
+		result.SolvedAt = &solvedAt
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// logRequests logs every request when running verbose
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		handler.ServeHTTP(w, r)
+		if flagVars.verbose {
+			log.Printf("%s %s %s", r.Method, r.URL, time.Since(start))
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// newTestServer returns the API of a server with the incidents of Sweden, reported on October 2019
+func newTestServer(incidents sla.Incidents) http.Handler {
+	config = Config{Countries: []Country{{Name: "Sweden",
+		SLAs: []sla.SLA{{Priority: "Critical", Hours: 4}, {Priority: "High", Hours: 8}, {Priority: "Medium", Days: 2}}}}}
+	flagVars.country, flagVars.month, flagVars.year = "Sweden", 10, 2019
+	flagVars.referenceFilename, flagVars.correctionsFilename, flagVars.where = "", "", ""
+	whereFilter = nil
+
+	mux := http.NewServeMux()
+	srv := &server{incidents: incidents}
+	srv.registerAPI(mux)
+	return mux
+}
+
+func Test_handleIncidents(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	handler := newTestServer(sla.Incidents{
+		{ID: "1", Country: "Sweden", FlagCorp: true, Priority: sla.Critical, Service: "CRM", CreatedAt: created,
+			SolvedAt: created.Add(time.Hour), SLAReady: true},
+		{ID: "2", Country: "Sweden", FlagCorp: true, Priority: sla.Critical, Service: "ERP", CreatedAt: created,
+			SolvedAt: created.Add(5 * time.Hour), SLAReady: true},
+		{ID: "3", Country: "Sweden", FlagCorp: true, Priority: sla.High, Service: "CRM", CreatedAt: created},
+		{ID: "4", Country: "Sweden", FlagCorp: true, Priority: sla.High, Service: "CRM", CreatedAt: created.AddDate(0, -1, 0),
+			SolvedAt: created.AddDate(0, -1, 0).Add(time.Hour), SLAReady: true},
+		{ID: "5", Country: "Norway", FlagCorp: true, Priority: sla.High, Service: "CRM", CreatedAt: created},
+		{ID: "6", Country: "Sweden", FlagCorp: true, Priority: sla.Medium, Service: "ERP", CreatedAt: created.AddDate(-1, 0, 0)},
+	})
+
+	for query, expected := range map[string][]string{
+		"":                                {"1", "2", "3", "4", "6"},
+		"?month=10":                       {"1", "2", "3"},
+		"?month=9&year=2019":              {"4"},
+		"?year=2019":                      {"1", "2", "3", "4"},
+		"?year=2018":                      {"6"},
+		"?priority=Critical":              {"1", "2"},
+		"?service=CRM":                    {"1", "3", "4"},
+		"?slamet=true":                    {"1", "4"},
+		"?month=10&priority=High":         {"3"},
+		"?slamet=false&priority=Critical": {"2"},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/incidents"+query, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %q, got %d: %s", query, recorder.Code, recorder.Body.String())
+			continue
+		}
+		var incidents []apiIncident
+		if err := json.Unmarshal(recorder.Body.Bytes(), &incidents); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, incident := range incidents {
+			ids = append(ids, incident.ID)
+		}
+		if len(ids) != len(expected) {
+			t.Errorf("Expected incidents %v for %q, got %v", expected, query, ids)
+			continue
+		}
+		for index := range ids {
+			if ids[index] != expected[index] {
+				t.Errorf("Expected incidents %v for %q, got %v", expected, query, ids)
+				break
+			}
+		}
+	}
+}
+
+func Test_badRequests(t *testing.T) {
+	handler := newTestServer(nil)
+	for _, url := range []string{
+		"/api/incidents?country=Norway",
+		"/api/incidents?month=13",
+		"/api/incidents?month=oct",
+		"/api/incidents?year=last",
+		"/api/incidents?priority=Urgent",
+		"/api/incidents?slamet=maybe",
+		"/api/report?month=0",
+		"/api/report.xlsx?country=Norway",
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", url, recorder.Code)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body["error"] == "" {
+			t.Errorf("Expected an error message for %s, got %s", url, recorder.Body.String())
+		}
+	}
+}
+
+func Test_handleReport(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incident := sla.Incident{ID: "1", Country: "Sweden", FlagCorp: true, Priority: sla.Critical, CreatedAt: created,
+		SolvedAt: created.Add(time.Hour), SLAReady: true}
+	handler := newTestServer(sla.Incidents{incident})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/report?month=10&year=2019", nil))
+	var data sla.ReportData
+	if err := json.Unmarshal(recorder.Body.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusOK || data.Country != "Sweden" || data.Month != 10 {
+		t.Errorf("Expected the report of Sweden in October, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if performance, ok := data.Areas[0].Priorities[sla.Critical].Months[5].Percentage(); !ok || performance != 1 {
+		t.Errorf("Expected a performance of 1 for Critical, got %v", performance)
+	}
+
+	// a corrected time that is not a duration stops the preparation
+	incident.Priority, incident.CorrectedTime = sla.Medium, "soon"
+	handler = newTestServer(sla.Incidents{incident})
+	for _, url := range []string{"/api/incidents", "/api/report", "/api/report.xlsx"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		var body map[string]string
+		_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+		if recorder.Code != http.StatusInternalServerError || body["error"] == "" {
+			t.Errorf("Expected status 500 with an error for %s, got %d: %s", url, recorder.Code, recorder.Body.String())
+		}
+	}
+}
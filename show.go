@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// showTimeFormat includes the weekday, to follow the business days of the SLA
+const showTimeFormat = "Mon 2006-01-02 15:04"
+
+// runShowCommand prints everything that went into the SLA result and availability of an incident,
+// to answer a dispute without redoing the maths by hand
+func runShowCommand(incidents sla.Incidents) error {
+	id := commandArgs[1]
+	index := findIncidentByID(incidents, id)
+	if index == -1 {
+		return &UsageError{Message: fmt.Sprintf("incident %s is not in %s", id, flagVars.inputFilename)}
+	}
+	imported := incidents[index]
+
+	countryConfig, err := getCountryFromConfig(config, imported.Country)
+	if err != nil {
+		return err
+	}
+	corporate, local, err := prepareIncidents(incidents, countryConfig, flagVars.month, flagVars.year)
+	if err != nil {
+		return err
+	}
+
+	// the incident as it is used in the report, if the filters keep it
+	prepared, inReport, note := imported, false, "no, it is filtered out by the category filters"
+	if index := findIncidentByID(corporate, id); index != -1 {
+		prepared, inReport, note = corporate[index], true, "yes"
+	} else if index := findIncidentByID(local, id); index != -1 {
+		prepared, note = local[index], "no, it is a local incident and only listed"
+	}
+
+	source, err := adjustmentSource(prepared, countryConfig.Name)
+	if err != nil {
+		return err
+	}
+	trace, err := sla.TraceIncident(prepared, sla.ParseSLAConfig(countryConfig.SLAs), countryConfig.SplitArea)
+	if err != nil {
+		return err
+	}
+	if !inReport {
+		trace.Counted = false
+		trace.Availability = false
+	}
+	writeIncidentTrace(os.Stdout, imported, prepared, note, source, trace)
+	return nil
+}
+
+// adjustmentSource returns where the exclusion or corrected time of an incident comes from:
+// the corrections file wins over the reference workbooks, which win over the rules
+func adjustmentSource(incident sla.Incident, country string) (string, error) {
+	if !incident.Exclude && incident.CorrectedTime == "" {
+		return "", nil
+	}
+
+	if flagVars.correctionsFilename != "" {
+		corrections, err := readCorrections(flagVars.correctionsFilename)
+		if err != nil {
+			return "", &AdjustmentError{Filename: flagVars.correctionsFilename, Err: err}
+		}
+		if correction, found := corrections.find(incident.ID); found && correction.adjusts() {
+			source := "corrections file " + flagVars.correctionsFilename
+			if correction.Date != "" {
+				source += " of " + correction.Date
+			}
+			return source, nil
+		}
+	}
+
+	if flagVars.referenceFilename != "" {
+		var sources []string
+		for _, filename := range getReferenceFilenames(flagVars.referenceFilename, country, flagVars.month, flagVars.year) {
+			corrections, err := readReferenceCorrections(filename)
+			if err != nil {
+				return "", err
+			}
+			reference := Corrections{Corrections: corrections}
+			if correction, found := reference.find(incident.ID); found && correction.adjusts() {
+				sources = append(sources, filename)
+			}
+		}
+		if len(sources) > 0 {
+			// with several workbooks the precedence decides, as in mergeReferenceCorrections
+			filename := sources[0]
+			if config.ReferencePrecedence == "last" {
+				filename = sources[len(sources)-1]
+			}
+			return "reference workbook " + filename, nil
+		}
+	}
+
+	if incident.Rule != "" {
+		return "rule " + incident.Rule, nil
+	}
+	return "", nil
+}
+
+// writeIncidentTrace prints the imported fields, the adjustments, the SLA computation and the availability
+func writeIncidentTrace(out io.Writer, imported sla.Incident, prepared sla.Incident, reportNote string, source string,
+	trace sla.Trace) {
+
+	formatTime := func(moment time.Time) string {
+		if moment.IsZero() {
+			return "-"
+		}
+		return moment.Format(showTimeFormat)
+	}
+	origin := "local"
+	if imported.FlagCorp {
+		origin = "corporate"
+	}
+
+	fmt.Fprintf(out, "Incident %s\n", imported.ID)
+	fmt.Fprintf(out, "  Country:        %s (%s)\n", imported.Country, origin)
+	fmt.Fprintf(out, "  Priority:       %s\n", sla.PriorityNames[imported.Priority])
+	fmt.Fprintf(out, "  Created:        %s\n", formatTime(imported.CreatedAt))
+	fmt.Fprintf(out, "  Solved:         %s\n", formatTime(imported.SolvedAt))
+	fmt.Fprintf(out, "  Open time:      %d minutes\n", imported.OpenTime)
+	fmt.Fprintf(out, "  Service:        %s\n", imported.Service)
+	fmt.Fprintf(out, "  Service CI:     %s\n", imported.ServiceCI)
+	fmt.Fprintf(out, "  Business area:  %s\n", imported.BusinessArea)
+	fmt.Fprintf(out, "  Category:       %s / %s\n", imported.ProdCategory1, imported.ProdCategory2)
+	fmt.Fprintf(out, "  Description:    %s\n", imported.Description)
+	fmt.Fprintf(out, "  Resolution:     %s\n", imported.Resolution)
+	fmt.Fprintf(out, "  Link:           %s\n", sla.IncidentURL(imported.ID))
+
+	fmt.Fprintf(out, "\nAdjustments\n")
+	fmt.Fprintf(out, "  In the report:  %s\n", reportNote)
+	if prepared.Rule != "" {
+		fmt.Fprintf(out, "  Rules:          %s\n", prepared.Rule)
+	}
+	if prepared.Priority != imported.Priority {
+		fmt.Fprintf(out, "  Priority:       %s, reclassified\n", sla.PriorityNames[prepared.Priority])
+	}
+	switch {
+	case prepared.Exclude:
+		fmt.Fprintf(out, "  Adjustment:     excluded\n")
+	case prepared.CorrectedTime != "":
+		fmt.Fprintf(out, "  Adjustment:     open time corrected to %s\n", prepared.CorrectedTime)
+	default:
+		fmt.Fprintf(out, "  Adjustment:     none\n")
+	}
+	if source != "" {
+		fmt.Fprintf(out, "  Source:         %s\n", source)
+	}
+	if prepared.Reason != "" {
+		fmt.Fprintf(out, "  Reason:         %s\n", prepared.Reason)
+	}
+	if prepared.Reviewer != "" {
+		fmt.Fprintf(out, "  Reviewer:       %s\n", prepared.Reviewer)
+	}
+
+	fmt.Fprintf(out, "\nSLA\n")
+	switch {
+	case trace.Days > 0:
+		fmt.Fprintf(out, "  Rule:           %s, %d business days after the day of creation\n",
+			sla.PriorityNames[prepared.Priority], trace.Days)
+	case trace.Hours > 0:
+		fmt.Fprintf(out, "  Rule:           %s, %d hours after creation\n", sla.PriorityNames[prepared.Priority], trace.Hours)
+	default:
+		fmt.Fprintf(out, "  Rule:           no SLA configured for %s, due at creation\n", sla.PriorityNames[prepared.Priority])
+	}
+	if len(trace.SkippedDays) > 0 {
+		var days []string
+		for _, day := range trace.SkippedDays {
+			days = append(days, day.Format("Mon 2006-01-02"))
+		}
+		fmt.Fprintf(out, "  Skipped:        %s\n", strings.Join(days, ", "))
+	}
+	fmt.Fprintf(out, "  Due:            %s\n", formatTime(trace.Due))
+	solved := formatTime(trace.Solved)
+	if prepared.CorrectedTime != "" {
+		solved += ", creation plus the corrected time"
+	}
+	fmt.Fprintf(out, "  Compared with:  %s\n", solved)
+	switch {
+	case prepared.Exclude:
+		fmt.Fprintf(out, "  Outcome:        not counted, excluded\n")
+	case !trace.Counted && prepared.SLAReady:
+		fmt.Fprintf(out, "  Outcome:        not counted, not in the report\n")
+	case !trace.Counted:
+		fmt.Fprintf(out, "  Outcome:        not counted, not solved\n")
+	case trace.Met:
+		fmt.Fprintf(out, "  Outcome:        met\n")
+	default:
+		fmt.Fprintf(out, "  Outcome:        breached by %s\n", trace.Solved.Sub(trace.Due).Round(time.Minute))
+	}
+	if !imported.SolvedAt.IsZero() && (prepared.Exclude || prepared.CorrectedTime != "") {
+		outcome := "breached"
+		if prepared.UncorrectedSLAMet {
+			outcome = "met"
+		}
+		fmt.Fprintf(out, "  Unadjusted:     %s\n", outcome)
+	}
+
+	fmt.Fprintf(out, "\nAvailability\n")
+	if !trace.Availability {
+		fmt.Fprintf(out, "  Counted:        no, only solved Critical incidents of the IT services in the report count\n")
+		return
+	}
+	fmt.Fprintf(out, "  Counted:        yes, for %s\n", prepared.Service)
+	for _, month := range trace.OutageMinutes {
+		fmt.Fprintf(out, "  %-15s %d minutes\n", fmt.Sprintf("%s %d:", sla.MonthNames[month.Month], month.Year), month.Minutes)
+	}
+}
@@ -0,0 +1,118 @@
+package sla
+
+import (
+	"sort"
+	"time"
+)
+
+// BacklogAge is an age bucket of the backlog, Below is the upper bound of the age or 0 for no bound
+type BacklogAge struct {
+	Name  string
+	Below time.Duration
+}
+
+// BacklogAges are the age buckets of the backlog, youngest first
+var BacklogAges = []BacklogAge{
+	{Name: "0-1d", Below: 24 * time.Hour},
+	{Name: "1-3d", Below: 3 * 24 * time.Hour},
+	{Name: "3-7d", Below: 7 * 24 * time.Hour},
+	{Name: "7-30d", Below: 30 * 24 * time.Hour},
+	{Name: ">30d"},
+}
+
+// BacklogData contains the incidents still open at the end of the report month, or at the time the report
+// is generated if that is earlier. Trend is the number of open incidents at the end of each of the 6 months.
+type BacklogData struct {
+	At         time.Time             `json:"at"`
+	Ages       []string              `json:"ages"`
+	Priorities []BacklogPriorityData `json:"priorities"`
+	Trend      []int                 `json:"trend"`
+
+	// the open incidents, oldest first
+	Incidents []BacklogIncident `json:"-"`
+}
+
+// BacklogPriorityData contains the number of open incidents of a priority per age bucket,
+// Overdue are the ones already past their SLA due time
+type BacklogPriorityData struct {
+	Priority string `json:"priority"`
+	Ages     []int  `json:"ages"`
+	Overdue  int    `json:"overdue"`
+	Total    int    `json:"total"`
+}
+
+// BacklogIncident is an open incident with its age at the time of the backlog
+type BacklogIncident struct {
+	Incident
+	Age     time.Duration
+	Bucket  string
+	Overdue bool
+}
+
+// isOpenAt tells whether an incident was created and not yet solved at the given time,
+// excluded incidents are not part of the backlog
+func (incident *Incident) isOpenAt(at time.Time) bool {
+	if incident.Exclude || incident.CreatedAt.IsZero() || !incident.CreatedAt.Before(at) {
+		return false
+	}
+	return incident.SolvedAt.IsZero() || incident.SolvedAt.After(at)
+}
+
+// endOfMonthOrNow returns the end of the month, or now if that is earlier
+func endOfMonthOrNow(month int, year int, now time.Time) time.Time {
+	nextMonth, nextYear := NextMonth(month, year)
+	end := time.Date(nextYear, time.Month(nextMonth), 1, 0, 0, 0, 0, time.UTC)
+	if now.Before(end) {
+		return now
+	}
+	return end
+}
+
+// BuildBacklog collects the open incidents at the end of the given month, or at now if that is earlier.
+// The incidents have to be checked against the SLA first, to have their due time.
+func BuildBacklog(incidents Incidents, month int, year int, now time.Time) BacklogData {
+	data := BacklogData{At: endOfMonthOrNow(month, year, now)}
+	for _, age := range BacklogAges {
+		data.Ages = append(data.Ages, age.Name)
+	}
+	for _, priority := range PriorityNames {
+		data.Priorities = append(data.Priorities, BacklogPriorityData{Priority: priority, Ages: make([]int, len(BacklogAges))})
+	}
+
+	for index := range incidents {
+		incident := &incidents[index]
+		if !incident.isOpenAt(data.At) {
+			continue
+		}
+		open := BacklogIncident{Incident: *incident, Age: data.At.Sub(incident.CreatedAt), Overdue: data.At.After(incident.Due)}
+		priorityData := &data.Priorities[incident.Priority]
+		for bucket, age := range BacklogAges {
+			if age.Below == 0 || open.Age < age.Below {
+				open.Bucket = age.Name
+				priorityData.Ages[bucket]++
+				break
+			}
+		}
+		if open.Overdue {
+			priorityData.Overdue++
+		}
+		priorityData.Total++
+		data.Incidents = append(data.Incidents, open)
+	}
+	sort.SliceStable(data.Incidents, func(i, j int) bool {
+		return data.Incidents[i].CreatedAt.Before(data.Incidents[j].CreatedAt)
+	})
+
+	reportMonth, reportYear := SubtractMonths(month, year, 5)
+	for index := 0; index < 6; index++ {
+		at, open := endOfMonthOrNow(reportMonth, reportYear, now), 0
+		for index := range incidents {
+			if incidents[index].isOpenAt(at) {
+				open++
+			}
+		}
+		data.Trend = append(data.Trend, open)
+		reportMonth, reportYear = NextMonth(reportMonth, reportYear)
+	}
+	return data
+}
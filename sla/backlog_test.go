@@ -0,0 +1,50 @@
+package sla
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_BuildBacklog(t *testing.T) {
+	slaSet := ParseSLAConfig([]SLA{{Priority: "Critical", Hours: 4}, {Priority: "Low", Days: 5}})
+	end := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	incidents, err := CheckIncidentsAgainstSLA(Incidents{
+		{ID: "1", Priority: Critical, CreatedAt: end.Add(-2 * time.Hour)},
+		{ID: "2", Priority: Critical, CreatedAt: end.Add(-10 * time.Hour)},
+		{ID: "3", Priority: Low, CreatedAt: end.AddDate(0, 0, -2)},
+		{ID: "4", Priority: Low, CreatedAt: end.AddDate(0, -2, 0), SolvedAt: end.AddDate(0, 0, 3), SLAReady: true},
+		{ID: "5", Priority: Low, CreatedAt: end.AddDate(0, 0, -5), SolvedAt: end.AddDate(0, 0, -1), SLAReady: true},
+		{ID: "6", Priority: Low, CreatedAt: end.AddDate(0, 0, -5), Exclude: true},
+		{ID: "7", Priority: Low, CreatedAt: end.Add(time.Hour)},
+	}, slaSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backlog := BuildBacklog(incidents, 10, 2019, end.AddDate(0, 1, 0))
+	if !backlog.At.Equal(end) {
+		t.Errorf("Expected the backlog at the end of October, got %v", backlog.At)
+	}
+	critical := backlog.Priorities[Critical]
+	if critical.Total != 2 || critical.Overdue != 1 || !reflect.DeepEqual(critical.Ages, []int{2, 0, 0, 0, 0}) {
+		t.Errorf("Expected 2 young Critical incidents of which 1 overdue, got %+v", critical)
+	}
+	low := backlog.Priorities[Low]
+	if low.Total != 2 || low.Overdue != 1 || !reflect.DeepEqual(low.Ages, []int{0, 1, 0, 0, 1}) {
+		t.Errorf("Expected 2 Low incidents of which the oldest is overdue, got %+v", low)
+	}
+	if len(backlog.Incidents) != 4 || backlog.Incidents[0].ID != "4" || backlog.Incidents[0].Bucket != ">30d" {
+		t.Errorf("Expected the open incidents oldest first, got %+v", backlog.Incidents)
+	}
+	if expected := []int{0, 0, 0, 0, 1, 4}; !reflect.DeepEqual(backlog.Trend, expected) {
+		t.Errorf("Expected the trend %v, got %v", expected, backlog.Trend)
+	}
+
+	// for the current month the backlog is taken at the time of the report
+	now := end.Add(-3 * time.Hour)
+	backlog = BuildBacklog(incidents, 10, 2019, now)
+	if !backlog.At.Equal(now) || backlog.Priorities[Critical].Total != 1 {
+		t.Errorf("Expected 1 Critical incident at %v, got %+v at %v", now, backlog.Priorities[Critical], backlog.At)
+	}
+}
@@ -0,0 +1,107 @@
+package sla
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CategoryFilter includes and excludes incidents by product category, for the whole country
+// or, if BusinessArea is set, for the incidents of that area only.
+// With include patterns only the matching incidents are kept, the exclude patterns remove incidents.
+type CategoryFilter struct {
+	BusinessArea string
+	Include      []CategoryPattern
+	Exclude      []CategoryPattern
+}
+
+// CategoryPattern matches the Tier 1 and Tier 2 product categories with wildcards, * and ?, ignoring case
+// an empty pattern matches any category
+type CategoryPattern struct {
+	Tier1 string
+	Tier2 string
+
+	tier1 *regexp.Regexp
+	tier2 *regexp.Regexp
+}
+
+// CompileCategoryFilters prepares the patterns of the filters, it has to be called before filtering
+func CompileCategoryFilters(filters []CategoryFilter) error {
+	for index := range filters {
+		for _, patterns := range [][]CategoryPattern{filters[index].Include, filters[index].Exclude} {
+			for index := range patterns {
+				if err := patterns[index].Compile(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Compile prepares the wildcards of the pattern, it has to be called before filtering
+func (pattern *CategoryPattern) Compile() error {
+	if pattern.Tier1 == "" && pattern.Tier2 == "" {
+		return fmt.Errorf("category pattern without tier1 or tier2")
+	}
+	pattern.tier1 = compileWildcard(pattern.Tier1)
+	pattern.tier2 = compileWildcard(pattern.Tier2)
+	return nil
+}
+
+// compileWildcard turns a pattern with * and ? into a regular expression, nil for an empty pattern
+func compileWildcard(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.Replace(expression, `\*`, ".*", -1)
+	expression = strings.Replace(expression, `\?`, ".", -1)
+	return regexp.MustCompile("(?i)^" + expression + "$")
+}
+
+// matches returns whether the product categories of the incident match the pattern
+func (pattern *CategoryPattern) matches(incident *Incident) bool {
+	return (pattern.tier1 == nil || pattern.tier1.MatchString(incident.ProdCategory1)) &&
+		(pattern.tier2 == nil || pattern.tier2.MatchString(incident.ProdCategory2))
+}
+
+// FilterByCategories applies the category filters of a country and returns the incidents that are kept
+// and the number of incidents removed
+func (incidents *Incidents) FilterByCategories(filters []CategoryFilter, options FilterOptions) (Incidents, int) {
+	if options.NoFilter {
+		return *incidents, 0
+	}
+	var result []Incident
+	for index := range *incidents {
+		incident := &(*incidents)[index]
+		if keepByCategories(incident, filters) != options.Reverse {
+			result = append(result, *incident)
+		}
+	}
+	return result, len(*incidents) - len(result)
+}
+
+// keepByCategories returns whether the incident passes the filters that apply to its business area:
+// it has to match an include pattern, if there are any, and may not match an exclude pattern
+func keepByCategories(incident *Incident, filters []CategoryFilter) bool {
+	hasInclude := false
+	included := false
+	for _, filter := range filters {
+		if filter.BusinessArea != "" && !strings.EqualFold(filter.BusinessArea, incident.BusinessArea) {
+			continue
+		}
+		for _, pattern := range filter.Exclude {
+			if pattern.matches(incident) {
+				return false
+			}
+		}
+		for _, pattern := range filter.Include {
+			hasInclude = true
+			if pattern.matches(incident) {
+				included = true
+			}
+		}
+	}
+	return included || !hasInclude
+}
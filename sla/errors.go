@@ -0,0 +1,17 @@
+package sla
+
+import "fmt"
+
+// IncidentError is invalid data of an incident, like a corrected time that is not a duration
+type IncidentError struct {
+	ID  string
+	Err error
+}
+
+func (err *IncidentError) Error() string {
+	return fmt.Sprintf("incident %s: %v", err.ID, err.Err)
+}
+
+func (err *IncidentError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,109 @@
+package sla
+
+import (
+	"sort"
+	"time"
+)
+
+// Forecast contains the open incidents that breached their SLA or are due within the horizon,
+// and the SLA performance of the month projected over the incidents still open
+type Forecast struct {
+	At             time.Time              `json:"at"`
+	Horizon        time.Duration          `json:"-"`
+	HorizonMinutes int                    `json:"horizonMinutes"`
+	AtRisk         []AtRiskIncident       `json:"atRisk"`
+	Priorities     []ForecastPriorityData `json:"priorities"`
+}
+
+// AtRiskIncident is an open incident with the time left until its due time, negative if it breached
+type AtRiskIncident struct {
+	ID        string    `json:"id"`
+	Priority  string    `json:"priority"`
+	CreatedAt time.Time `json:"createdAt"`
+	Due       time.Time `json:"due"`
+	Service   string    `json:"service"`
+
+	Remaining        time.Duration `json:"-"`
+	RemainingMinutes int           `json:"remainingMinutes"`
+}
+
+// ForecastPriorityData contains the incidents of a priority created in the month: the solved ones counted
+// for the SLA and the open ones of which Breached are already past their due time.
+// Best assumes the open incidents that did not breach yet are solved in time, Worst that all open
+// incidents breach. The percentages are nil if there are no incidents to calculate them on.
+type ForecastPriorityData struct {
+	Priority string   `json:"priority"`
+	Target   float64  `json:"target"`
+	Total    int      `json:"total"`
+	SLAMet   int      `json:"slaMet"`
+	Open     int      `json:"open"`
+	Breached int      `json:"breached"`
+	Current  *float64 `json:"current"`
+	Best     *float64 `json:"best"`
+	Worst    *float64 `json:"worst"`
+}
+
+// ForecastSLA computes the time left for the incidents open at now, or at the end of the month if that is
+// earlier, and projects the SLA performance of the month. The incidents have to be checked against the SLA
+// first, to have their due time. The projection does not carry over months below the minimum incidents.
+func ForecastSLA(incidents Incidents, month int, year int, now time.Time, horizon time.Duration) Forecast {
+	at := endOfMonthOrNow(month, year, now)
+	forecast := Forecast{At: at, Horizon: horizon, HorizonMinutes: int(horizon.Minutes())}
+	for _, priority := range PriorityNames {
+		forecast.Priorities = append(forecast.Priorities, ForecastPriorityData{Priority: priority, Target: SLATarget})
+	}
+
+	for index := range incidents {
+		incident := &incidents[index]
+		inMonth := int(incident.CreatedAt.Month()) == month && incident.CreatedAt.Year() == year
+		priorityData := &forecast.Priorities[incident.Priority]
+		if !incident.isOpenAt(at) {
+			if inMonth && incident.SLAReady {
+				priorityData.Total++
+				if incident.SLAMet {
+					priorityData.SLAMet++
+				}
+			}
+			continue
+		}
+
+		remaining := incident.Due.Sub(at)
+		if inMonth {
+			priorityData.Open++
+			if remaining < 0 {
+				priorityData.Breached++
+			}
+		}
+		if remaining < horizon {
+			forecast.AtRisk = append(forecast.AtRisk, AtRiskIncident{
+				ID:        incident.ID,
+				Priority:  PriorityNames[incident.Priority],
+				CreatedAt: incident.CreatedAt,
+				Due:       incident.Due,
+				Service:   incident.Service,
+
+				Remaining:        remaining,
+				RemainingMinutes: int(remaining.Minutes()),
+			})
+		}
+	}
+	sort.SliceStable(forecast.AtRisk, func(i, j int) bool {
+		return forecast.AtRisk[i].Remaining < forecast.AtRisk[j].Remaining
+	})
+
+	percentage := func(met int, total int) *float64 {
+		if total == 0 {
+			return nil
+		}
+		value := float64(met) / float64(total)
+		return &value
+	}
+	for index := range forecast.Priorities {
+		priorityData := &forecast.Priorities[index]
+		priorityData.Current = percentage(priorityData.SLAMet, priorityData.Total)
+		priorityData.Best = percentage(priorityData.SLAMet+priorityData.Open-priorityData.Breached,
+			priorityData.Total+priorityData.Open)
+		priorityData.Worst = percentage(priorityData.SLAMet, priorityData.Total+priorityData.Open)
+	}
+	return forecast
+}
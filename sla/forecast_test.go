@@ -0,0 +1,47 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ForecastSLA(t *testing.T) {
+	slaSet := ParseSLAConfig([]SLA{{Priority: "Critical", Hours: 4}, {Priority: "High", Hours: 8}})
+	now := time.Date(2019, 10, 15, 12, 0, 0, 0, time.UTC)
+	incidents, err := CheckIncidentsAgainstSLA(Incidents{
+		{ID: "1", Priority: Critical, CreatedAt: now.Add(-5 * time.Hour)},
+		{ID: "2", Priority: Critical, CreatedAt: now.Add(-time.Hour)},
+		{ID: "3", Priority: Critical, CreatedAt: now.AddDate(0, 0, -2), SolvedAt: now.AddDate(0, 0, -2).Add(time.Hour),
+			SLAReady: true},
+		{ID: "4", Priority: High, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "5", Priority: High, CreatedAt: now.AddDate(0, -1, 0)},
+	}, slaSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forecast := ForecastSLA(incidents, 10, 2019, now, 4*time.Hour)
+	var ids []string
+	for _, incident := range forecast.AtRisk {
+		ids = append(ids, incident.ID)
+	}
+	// the incident of September breached long ago, 4 is due in 6 hours and not at risk yet
+	if len(ids) != 3 || ids[0] != "5" || ids[1] != "1" || ids[2] != "2" {
+		t.Errorf("Expected incidents 5, 1 and 2 sorted by the time left, got %v", ids)
+	}
+	if forecast.AtRisk[2].Remaining != 3*time.Hour {
+		t.Errorf("Expected 3 hours left for incident 2, got %v", forecast.AtRisk[2].Remaining)
+	}
+
+	critical := forecast.Priorities[Critical]
+	if critical.Total != 1 || critical.Open != 2 || critical.Breached != 1 {
+		t.Errorf("Expected 1 solved and 2 open Critical incidents of which 1 breached, got %+v", critical)
+	}
+	if *critical.Current != 1 || *critical.Best != 2.0/3 || *critical.Worst != 1.0/3 {
+		t.Errorf("Expected current 1, best 2/3 and worst 1/3, got %v, %v and %v", *critical.Current, *critical.Best, *critical.Worst)
+	}
+	high := forecast.Priorities[High]
+	if high.Open != 1 || high.Current != nil || *high.Best != 1 || *high.Worst != 0 {
+		t.Errorf("Expected only the open High incident of October to count, got %+v", high)
+	}
+}
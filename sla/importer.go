@@ -1,11 +1,11 @@
-package main
+package sla
 
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
-	"log"
 	"os"
 	"strings"
 	"time"
@@ -17,9 +17,9 @@ import (
 func ImportIncidents(filename string) (Incidents, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		log.Printf("Trying to open %s: %v", filename, err)
 		return nil, err
 	}
+	defer file.Close()
 
 	// read the UTF-16 file
 	scanner := bufio.NewScanner(transform.NewReader(
@@ -33,14 +33,29 @@ func ImportIncidents(filename string) (Incidents, error) {
 	headerParts := strings.Split(scanner.Text(), "\t")
 	headers, err := parseHeaders(headerParts)
 	if err != nil {
-		log.Printf("Error parsing header: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("parsing header: %v", err)
+	}
+
+	// a row needs the columns up to the last one read, a shorter one is truncated
+	columns := headers["Flag corp/local"] + 1
+	for _, field := range requiredFields {
+		if headers[field] >= columns {
+			columns = headers[field] + 1
+		}
 	}
 
 	// loop through the lines reading the incidents
 	var incidents []Incident
+	line := 1
 	for scanner.Scan() {
+		line++
+		if scanner.Text() == "" {
+			continue
+		}
 		parts := strings.Split(scanner.Text(), "\t")
+		if len(parts) < columns {
+			return nil, fmt.Errorf("line %d: %d columns, expected %d", line, len(parts), columns)
+		}
 		var inc = Incident{
 			Country:       parts[headers["Country"]],
 			ID:            parts[headers["Incident Number"]],
@@ -88,26 +103,27 @@ func ImportIncidents(filename string) (Incidents, error) {
 	return incidents, nil
 }
 
+// requiredFields are the columns the incidents are read from
+var requiredFields = []string{
+	"Country",
+	"Incident Number",
+	"Create DateTime",
+	"Last Resolved DateTime",
+	"Priority",
+	"Product Categorization Tier1",
+	"Product Categorization Tier2",
+	"Service",
+	"Service CI",
+	"Business area",
+	"Status",
+	"Description",
+	"Resolution Description",
+}
+
 // map the headers to their position, this allows the source file to change layout without breaking
 // the loading of incidents
 // the fields are hardcoded in the list requiredFields.
 func parseHeaders(headerParts []string) (map[string]int, error) {
-	requiredFields := []string{
-		"Country",
-		"Incident Number",
-		"Create DateTime",
-		"Last Resolved DateTime",
-		"Priority",
-		"Product Categorization Tier1",
-		"Product Categorization Tier2",
-		"Service",
-		"Service CI",
-		"Business area",
-		"Status",
-		"Description",
-		"Resolution Description",
-	}
-
 	headers := make(map[string]int)
 	for index, header := range headerParts {
 		headers[header] = index
@@ -0,0 +1,49 @@
+package sla
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+// writeIncidentsFile writes the lines as the UTF-16 tab-delimited export
+func writeIncidentsFile(t *testing.T, filename string, lines []string) string {
+	dat, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(strings.Join(lines, "\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filename, []byte(dat), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func Test_ImportIncidents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goreport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "incidents.csv")
+
+	header := strings.Join(append(append([]string{}, requiredFields...), "Flag corp/local"), "\t")
+	row := "Sweden\tINC1\t2019/10/07 09:00:00\t2019/10/07 10:30:00\tHigh\tSoftware\tCRM\tCRM\tcrm01\tIT\tClosed\tslow\tfixed\t1"
+
+	incidents, err := ImportIncidents(writeIncidentsFile(t, filename, []string{header, row}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 1 || incidents[0].ID != "INC1" || incidents[0].OpenTime != 90 || !incidents[0].FlagCorp {
+		t.Errorf("Expected incident INC1 open for 90 minutes, got %+v", incidents)
+	}
+
+	// a truncated row is an error with its line number instead of a panic
+	_, err = ImportIncidents(writeIncidentsFile(t, filename, []string{header, row, "Sweden\tINC2\t2019/10/07 09:00:00"}))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error for line 3, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package sla imports incidents and computes the SLA performance and service availability reported by goreport.
+// It has no global state: what the command line sets with flags and the configuration file is passed in Options.
+package sla
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Incidents type contains and array of Incident
+type Incidents []Incident
+
+// Incident struct described an incident
+type Incident struct {
+	Country           string
+	ID                string
+	CreatedAt         time.Time
+	SolvedAt          time.Time
+	Priority          int
+	Description       string
+	Resolution        string
+	Service           string
+	ProdCategory1     string
+	ProdCategory2     string
+	ServiceCI         string
+	BusinessArea      string
+	FlagCorp          bool
+	SLAReady          bool
+	SLAMet            bool
+	OpenTime          int
+	CorrectedTime     string        // corrected time in string format
+	CorrectedSolved   time.Time     // the new corrected solved time
+	CorrectedOpenTime time.Duration // the open time as duration
+	Exclude           bool
+	Reason            string    // why the incident is excluded or its time corrected
+	Reviewer          string    // who decided to exclude or correct
+	UncorrectedSLAMet bool      // the SLA outcome without exclusion and corrected time
+	Rule              string    // the rules that excluded or reclassified the incident
+	Due               time.Time // the SLA due time, also set for incidents that are still open
+}
+
+// usmsURLFormat is the link to an incident in USMS, the incident ID is filled in at %s
+const usmsURLFormat = "http://usms.upc.biz/arsys/forms/appusms/SHR%%3ALandingConsole/Default+Administrator+View/" +
+	"?mode=search&F304255500=HPD%%3AHelp+Desk&F1000000076=FormOpenNoAppList&F303647600=" +
+	"SearchTicketWithQual&F304255610='1000000161'%%3D%%22%s%%22"
+
+// IncidentURL returns the link to open the incident in USMS
+func IncidentURL(ID string) string {
+	return fmt.Sprintf(usmsURLFormat, ID)
+}
+
+// FilterOptions change how the corporate/local and category filters are applied:
+// NoFilter keeps all incidents, Reverse keeps the incidents the filter would remove instead
+type FilterOptions struct {
+	NoFilter bool
+	Reverse  bool
+}
+
+// FilterCorpLocal returns the corporate incidents, or the local ones if corp is false
+func (incidents *Incidents) FilterCorpLocal(corp bool, options FilterOptions) Incidents {
+	var result []Incident
+
+	if options.NoFilter {
+		return *incidents
+	}
+
+	if options.Reverse {
+		corp = !corp
+	}
+
+	for _, incident := range *incidents {
+		if incident.FlagCorp == corp {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByCountry(country string) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if strings.Contains(incident.Country, country) {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByMonthYear(month int, year int) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if int(incident.CreatedAt.Month()) == month && incident.CreatedAt.Year() == year {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByYear(year int) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if incident.CreatedAt.Year() == year {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByPriority(priority int) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if incident.Priority == priority {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByBusinessArea(area string) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if incident.BusinessArea == area {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterByService(service string) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if strings.EqualFold(incident.Service, service) {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) FilterBySLAMet(slaMet bool) Incidents {
+	var result []Incident
+	for _, incident := range *incidents {
+		if incident.SLAMet == slaMet {
+			result = append(result, incident)
+		}
+	}
+	return result
+}
+
+func (incidents *Incidents) CollectProdCategories() map[string]ProdCategory {
+	prodCategories := make(map[string]ProdCategory)
+	for _, incident := range *incidents {
+		category, found := prodCategories[incident.ProdCategory2]
+		if !found {
+			category = ProdCategory{}
+			prodCategories[incident.ProdCategory2] = category
+		}
+		category.Total++
+		if incident.SLAMet {
+			category.SLAMet++
+		}
+		switch incident.Priority {
+		case Critical:
+			category.Critical++
+		case High:
+			category.High++
+		case Medium:
+			category.Medium++
+		case Low:
+			category.Low++
+		}
+		prodCategories[incident.ProdCategory2] = category
+	}
+	return prodCategories
+}
+
+// SortProdCategoryNames returns the names of the product categories, the ones with the most incidents first
+func SortProdCategoryNames(categories map[string]ProdCategory) []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if categories[names[i]].Total != categories[names[j]].Total {
+			return categories[names[i]].Total > categories[names[j]].Total
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (incidents *Incidents) SixMonthsIncidents(month int, year int) Incidents {
+	var sixMonthIncidents Incidents
+
+	// start 6 months ago
+	month, year = SubtractMonths(month, year, 5)
+
+	// repeat for 6 months
+	for index := 0; index < 6; index++ {
+		sixMonthIncidents = append(sixMonthIncidents, incidents.FilterByMonthYear(month, year)...)
+		// advance month, check for year rollover
+		month, year = NextMonth(month, year)
+	}
+
+	return sixMonthIncidents
+}
+
+// CalculateITAvailability calculates the availability of the IT services for the 6 months
+// up to and including the given month
+func (incidents *Incidents) CalculateITAvailability(month int, year int) ServiceAvailability {
+	// define the period, starting 6 months back from the reporting month
+	startMonth, startYear := SubtractMonths(month, year, 5)
+	period := ReportPeriod{
+		startMonth: startMonth,
+		startYear:  startYear,
+		endMonth:   month,
+		endYear:    year,
+	}
+
+	// calculate the availability, use all incidents to include the ones rolling over into the period
+	return calculateSA(*incidents, ITServicesNames, period)
+}
+
+// getOutageMinutesInMonth returns the minutes of the outage of the incident that fall in the month.
+// The outage is the open time, or the corrected time, up to the moment the incident was solved,
+// so an incident rolling over from the month before only counts with its minutes in this month.
+func (incident *Incident) getOutageMinutesInMonth(month int, year int) int {
+	start, end := incident.outage()
+	location := incident.CreatedAt.Location()
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, location)
+	nextMonth, nextYear := NextMonth(month, year)
+	monthEnd := time.Date(nextYear, time.Month(nextMonth), 1, 0, 0, 0, 0, location)
+
+	if start.Before(monthStart) {
+		start = monthStart
+	}
+	if end.After(monthEnd) {
+		end = monthEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start).Minutes())
+}
+
+// outage returns the start and end of the outage of a solved incident
+func (incident *Incident) outage() (time.Time, time.Time) {
+	if incident.CorrectedTime != "" {
+		return incident.CreatedAt, incident.CreatedAt.Add(incident.CorrectedOpenTime)
+	}
+	return incident.SolvedAt.Add(-time.Duration(incident.OpenTime) * time.Minute), incident.SolvedAt
+}
+
+func PreviousMonth(month int, year int) (int, int) {
+	month--
+	if month == 0 {
+		month = 12
+		year--
+	}
+	return month, year
+}
+
+func NextMonth(month int, year int) (int, int) {
+	month++
+	if month == 13 {
+		month = 1
+		year++
+	}
+	return month, year
+}
+
+func SubtractMonths(month int, year int, delta int) (int, int) {
+	month -= delta
+	if month < 1 {
+		month += 12
+		year--
+	}
+	return month, year
+}
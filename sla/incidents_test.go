@@ -1,22 +1,48 @@
-package main
+package sla
 
 import (
 	"testing"
 	"time"
 )
 
-func TestIncidents_filterOutProdCategories(t *testing.T) {
+func TestIncidents_filterByCategories(t *testing.T) {
 	i1 := Incident{ID: "1", ProdCategory2: "foo"}
 	i2 := Incident{ID: "2", ProdCategory2: "foo"}
 	i3 := Incident{ID: "3", ProdCategory2: "bar"}
 
 	incidents := Incidents{i1, i2, i3}
-	prodCategories := []string{"bar"}
+	filters := []CategoryFilter{{Exclude: []CategoryPattern{{Tier2: "bar"}}}}
+	if err := CompileCategoryFilters(filters); err != nil {
+		t.Fatal(err)
+	}
 
-	filtered := incidents.filterOutProdCategories2(prodCategories)
-	if len(filtered) != 2 {
+	filtered, removed := incidents.FilterByCategories(filters, FilterOptions{})
+	if len(filtered) != 2 || removed != 1 {
 		t.Errorf("Exepcted length of 2, got %d", len(filtered))
 	}
+	filtered, _ = incidents.FilterByCategories(filters, FilterOptions{Reverse: true})
+	if len(filtered) != 1 || filtered[0].ID != "3" {
+		t.Errorf("Exepcted only incident 3 in reverse, got %v", filtered)
+	}
+
+	incidents = Incidents{
+		{ID: "1", BusinessArea: "IT", ProdCategory1: "Software", ProdCategory2: "CRM"},
+		{ID: "2", BusinessArea: "IT", ProdCategory1: "Hardware", ProdCategory2: "Server"},
+		{ID: "3", BusinessArea: "IT", ProdCategory1: "Software", ProdCategory2: "Test CRM"},
+		{ID: "4", BusinessArea: "Network", ProdCategory1: "Hardware", ProdCategory2: "Radio"},
+		{ID: "5", BusinessArea: "Network", ProdCategory1: "Hardware", ProdCategory2: "Lab radio"},
+	}
+	filters = []CategoryFilter{
+		{BusinessArea: "it", Include: []CategoryPattern{{Tier1: "soft*"}}},
+		{Exclude: []CategoryPattern{{Tier2: "test *"}, {Tier1: "Hardware", Tier2: "lab ?adio"}}},
+	}
+	if err := CompileCategoryFilters(filters); err != nil {
+		t.Fatal(err)
+	}
+	filtered, removed = incidents.FilterByCategories(filters, FilterOptions{})
+	if len(filtered) != 2 || filtered[0].ID != "1" || filtered[1].ID != "4" || removed != 3 {
+		t.Errorf("Expected incidents 1 and 4, got %v", filtered)
+	}
 }
 
 func TestIncidents_filterByCountry(t *testing.T) {
@@ -25,7 +51,7 @@ func TestIncidents_filterByCountry(t *testing.T) {
 	i3 := Incident{ID: "3", Country: "foo"}
 
 	incidents := Incidents{i1, i2, i3}
-	filtered := incidents.filterByCountry("foo")
+	filtered := incidents.FilterByCountry("foo")
 	if len(filtered) != 2 {
 		t.Errorf("Exepcted length of 2, got %d", len(filtered))
 	}
@@ -37,7 +63,7 @@ func TestIncidents_filterByPriority(t *testing.T) {
 	i3 := Incident{ID: "3", Priority: Critical}
 
 	incidents := Incidents{i1, i2, i3}
-	filtered := incidents.filterByPriority(Critical)
+	filtered := incidents.FilterByPriority(Critical)
 	if len(filtered) != 2 {
 		t.Errorf("Exepcted length of 2, got %d", len(filtered))
 	}
@@ -78,7 +104,7 @@ func Test_subtractMonths(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := subtractMonths(tt.args.month, tt.args.year, tt.args.delta)
+			got, got1 := SubtractMonths(tt.args.month, tt.args.year, tt.args.delta)
 			if got != tt.want {
 				t.Errorf("subtractMonths() got = %v, want %v", got, tt.want)
 			}
@@ -121,7 +147,7 @@ func Test_getNextMonth(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := getNextMonth(tt.args.month, tt.args.year)
+			got, got1 := NextMonth(tt.args.month, tt.args.year)
 			if got != tt.want {
 				t.Errorf("getNextMonth() got = %v, want %v", got, tt.want)
 			}
@@ -164,7 +190,7 @@ func Test_getPreviousMonth(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := getPreviousMonth(tt.args.month, tt.args.year)
+			got, got1 := PreviousMonth(tt.args.month, tt.args.year)
 			if got != tt.want {
 				t.Errorf("getPreviousMonth() got = %v, want %v", got, tt.want)
 			}
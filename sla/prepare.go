@@ -0,0 +1,103 @@
+package sla
+
+import "time"
+
+// Options drive the preparation of the incidents of a country and the numbers of its report.
+// The rules and category patterns have to be compiled before.
+type Options struct {
+	Country          string
+	Month            int
+	Year             int
+	SLAs             []SLA
+	MinimumIncidents MinimumIncidents
+	SplitArea        bool
+	Rules            []Rule
+	CategoryFilters  []CategoryFilter
+	Filter           FilterOptions
+
+	// Where limits the incidents to the ones matching the expression, nil for all incidents
+	Where Expression
+
+	// Adjust applies the exclusions and corrected times decided on outside the rules,
+	// it is called after the rules and filters and before the SLA check, an error stops the preparation.
+	// The warnings it returns are added to the report data.
+	Adjust func(incidents Incidents) (Incidents, []string, error)
+
+	// Logf receives the progress messages, nil to discard them
+	Logf func(format string, v ...interface{})
+}
+
+func (options *Options) logf(format string, v ...interface{}) {
+	if options.Logf != nil {
+		options.Logf(format, v...)
+	}
+}
+
+// PrepareIncidents reduces the incidents to the ones of the country, applies the rules, filters and adjustments
+// and checks the incidents against the SLA of the country.
+// It returns the corporate incidents and the local incidents
+func PrepareIncidents(incidents Incidents, options Options) (Incidents, Incidents, error) {
+	incidents, localIncidents, _, err := prepareIncidents(incidents, options)
+	return incidents, localIncidents, err
+}
+
+// prepareIncidents is PrepareIncidents that also returns the warnings of the adjustments
+func prepareIncidents(incidents Incidents, options Options) (Incidents, Incidents, []string, error) {
+	incidents = incidents.FilterByCountry(options.Country)
+
+	// the rules exclude and reclassify incidents, before any adjustments
+	if len(options.Rules) > 0 {
+		var counts map[string]int
+		incidents, counts = ApplyRules(incidents, options.Rules)
+		for _, rule := range options.Rules {
+			options.logf("Rule %s changed %d incidents", rule.Name, counts[rule.Name])
+		}
+	}
+
+	// the category filters leave out incidents that do not count for the SLA of the country
+	if len(options.CategoryFilters) > 0 && !options.Filter.NoFilter {
+		var removed int
+		incidents, removed = incidents.FilterByCategories(options.CategoryFilters, options.Filter)
+		options.logf("Category filters removed %d incidents of %s, %d left", removed, options.Country, len(incidents))
+	}
+
+	localIncidents := incidents.FilterCorpLocal(false, options.Filter)
+	incidents = incidents.FilterCorpLocal(true, options.Filter)
+
+	var warnings []string
+	var err error
+	if options.Adjust != nil {
+		incidents, warnings, err = options.Adjust(incidents)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	incidents, err = CheckIncidentsAgainstSLA(incidents, ParseSLAConfig(options.SLAs))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the where expression is applied last, so it can use the SLA outcome
+	if options.Where != nil {
+		incidents = incidents.FilterWhere(options.Where)
+		localIncidents = localIncidents.FilterWhere(options.Where)
+		options.logf("%d incidents of %s match the where expression", len(incidents), options.Country)
+	}
+	return incidents, localIncidents, warnings, nil
+}
+
+// NewReportData prepares the incidents and computes the numbers of the report, with the backlog at the time
+// the report is generated
+func NewReportData(incidents Incidents, options Options) (ReportData, error) {
+	incidents, localIncidents, warnings, err := prepareIncidents(incidents, options)
+	if err != nil {
+		return ReportData{}, err
+	}
+	data := BuildReportData(incidents, localIncidents, options.Country, options.Month, options.Year,
+		options.SplitArea, options.MinimumIncidents)
+	data.Warnings = warnings
+	data.GeneratedAt = time.Now().UTC()
+	data.Backlog = BuildBacklog(incidents, options.Month, options.Year, data.GeneratedAt)
+	return data, nil
+}
@@ -0,0 +1,259 @@
+package sla
+
+import "time"
+
+// ReportSchemaVersion is the version of the ReportData layout as exported to JSON and CSV.
+// It is incremented whenever a field is renamed or removed or its meaning changes,
+// adding fields does not change the version.
+const ReportSchemaVersion = 1
+
+// MinimumIncidents used for TTR performance measurement
+// If minimum not reached, value will carry over to the next month
+type MinimumIncidents struct {
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+}
+
+// ReportData contains all computed numbers of a report, independent of the output format
+type ReportData struct {
+	SchemaVersion  int                `json:"schemaVersion"`
+	Country        string             `json:"country"`
+	Month          int                `json:"month"`
+	Year           int                `json:"year"`
+	GeneratedAt    time.Time          `json:"generatedAt"`
+	Months         []ReportMonth      `json:"months"`
+	Areas          []AreaData         `json:"areas"`
+	ProdCategories []ProdCategoryData `json:"prodCategories"`
+	Backlog        BacklogData        `json:"backlog"`
+
+	// the problems found preparing the report that do not stop it, like conflicting reference workbooks
+	Warnings []string `json:"warnings,omitempty"`
+
+	// the incidents of the 6 months, used by the renderers that list them
+	Incidents      Incidents `json:"-"`
+	LocalIncidents Incidents `json:"-"`
+}
+
+// ReportMonth is one of the 6 months of the report, oldest first
+type ReportMonth struct {
+	Month int    `json:"month"`
+	Year  int    `json:"year"`
+	Name  string `json:"name"`
+}
+
+// AreaData contains the numbers for a business area, the name is empty if the areas are not split
+// Availability is only filled in for IT
+type AreaData struct {
+	Name         string                    `json:"name"`
+	Priorities   []PriorityData            `json:"priorities"`
+	Availability []ServiceAvailabilityData `json:"availability,omitempty"`
+}
+
+// PriorityData contains the monthly numbers for a priority
+type PriorityData struct {
+	Priority string              `json:"priority"`
+	Target   float64             `json:"target"`
+	Months   []PriorityMonthData `json:"months"`
+}
+
+// PriorityMonthData contains the numbers for a priority in a month.
+// Total and SLAMet are the incidents in that month, CalcTotal and CalcSLAMet are the values used
+// for the performance after carrying over months that did not reach the minimum number of incidents.
+// Performance is nil if there are no incidents to calculate it on.
+type PriorityMonthData struct {
+	Total       int      `json:"total"`
+	SLAMet      int      `json:"slaMet"`
+	CalcTotal   int      `json:"calcTotal"`
+	CalcSLAMet  int      `json:"calcSlaMet"`
+	Performance *float64 `json:"performance"`
+}
+
+// ServiceAvailabilityData contains the availability of a service for each month, as a fraction
+type ServiceAvailabilityData struct {
+	Service string    `json:"service"`
+	Target  float64   `json:"target"`
+	Months  []float64 `json:"months"`
+}
+
+// ProdCategoryData contains the incident counts of a product category over the 6 months
+type ProdCategoryData struct {
+	Name     string `json:"name"`
+	Total    int    `json:"total"`
+	SLAMet   int    `json:"slaMet"`
+	Critical int    `json:"critical"`
+	High     int    `json:"high"`
+	Medium   int    `json:"medium"`
+	Low      int    `json:"low"`
+}
+
+// Percentage returns the SLA performance, the boolean is false if there are no incidents to calculate it on
+func (monthData *PriorityMonthData) Percentage() (float64, bool) {
+	if monthData.Performance == nil {
+		return 0, false
+	}
+	return *monthData.Performance, true
+}
+
+// MonthNames returns the names of the 6 months of the report
+func (data *ReportData) MonthNames() []string {
+	var names []string
+	for _, month := range data.Months {
+		names = append(names, month.Name)
+	}
+	return names
+}
+
+// BuildReportData computes all numbers of the report for the 6 months up to and including the given month.
+// It does not depend on any output format and does not set GeneratedAt, leaving that to the caller.
+func BuildReportData(incidents Incidents, localIncidents Incidents, country string, month int, year int,
+	splitArea bool, minimumIncidents MinimumIncidents) ReportData {
+
+	data := ReportData{
+		SchemaVersion: ReportSchemaVersion,
+		Country:       country,
+		Month:         month,
+		Year:          year,
+	}
+
+	reportMonth, reportYear := SubtractMonths(month, year, 5)
+	for index := 0; index < 6; index++ {
+		data.Months = append(data.Months, ReportMonth{Month: reportMonth, Year: reportYear, Name: MonthNames[reportMonth]})
+		reportMonth, reportYear = NextMonth(reportMonth, reportYear)
+	}
+
+	areas := []string{""}
+	if splitArea {
+		areas = []string{"IT", "Network"}
+	}
+
+	for _, area := range areas {
+		areaIncidents := incidents
+		if area != "" {
+			areaIncidents = incidents.FilterByBusinessArea(area)
+		}
+		areaData, sixMonthIncidents := calculateAreaData(areaIncidents, area, month, year, minimumIncidents)
+		data.Areas = append(data.Areas, areaData)
+		data.Incidents = append(data.Incidents, sixMonthIncidents...)
+	}
+	data.LocalIncidents = localIncidents.SixMonthsIncidents(month, year)
+
+	categories := data.Incidents.CollectProdCategories()
+	for _, name := range SortProdCategoryNames(categories) {
+		category := categories[name]
+		data.ProdCategories = append(data.ProdCategories, ProdCategoryData{
+			Name:     name,
+			Total:    category.Total,
+			SLAMet:   category.SLAMet,
+			Critical: category.Critical,
+			High:     category.High,
+			Medium:   category.Medium,
+			Low:      category.Low,
+		})
+	}
+
+	return data
+}
+
+// calculateAreaData counts the incidents of an area for the 6 months up to and including the given month
+// it returns the numbers and all incidents created in those 6 months
+func calculateAreaData(incidents Incidents, area string, month int, year int,
+	minimumIncidentsConfig MinimumIncidents) (AreaData, Incidents) {
+
+	var totalIncidents [7][4]int
+	var slaMetIncidents [7][4]int
+	var calcTotalIncidents [7][4]int
+	var calcSLAMetIncidents [7][4]int
+
+	// to collect incidents for 'Incidents' tab, contains all incidents for 6 months
+	var sixMonthIncidents Incidents
+
+	// start 6 months ago
+	reportMonth, reportYear := SubtractMonths(month, year, 5)
+
+	// repeat for 6 months
+	for index := 0; index < 6; index++ {
+
+		// get incidents for a month
+		// add them to the grand list
+		monthIncidents := incidents.FilterByMonthYear(reportMonth, reportYear)
+		sixMonthIncidents = append(sixMonthIncidents, monthIncidents...)
+
+		// go through all priorities
+		// iterate over all incidents for that priority
+		// and update the 2 counters
+		for _, priority := range []int{Critical, High, Medium, Low} {
+			priorityIncidents := monthIncidents.FilterByPriority(priority)
+			for _, incident := range priorityIncidents {
+				if incident.SLAReady {
+					totalIncidents[index][priority]++
+					if incident.SLAMet {
+						slaMetIncidents[index][priority]++
+					}
+				}
+			}
+
+			// copy to the value used to calculate performance
+			calcTotalIncidents[index][priority] = totalIncidents[index][priority]
+			calcSLAMetIncidents[index][priority] = slaMetIncidents[index][priority]
+		}
+
+		// advance month, check for year rollover
+		reportMonth, reportYear = NextMonth(reportMonth, reportYear)
+	}
+
+	// process minimum incidents config
+	var minimumIncidents [4]int
+	minimumIncidents[0] = minimumIncidentsConfig.Critical
+	minimumIncidents[1] = minimumIncidentsConfig.High
+	minimumIncidents[2] = minimumIncidentsConfig.Medium
+	minimumIncidents[3] = minimumIncidentsConfig.Low
+
+	// run through the 6 months to check the minimum incident threshold
+	// if the minimum is not reached, it moves forward until it is
+	// or the end of the report is reached
+	for index := 0; index < 6; index++ {
+		for priority := Critical; priority <= Low; priority++ {
+			if calcTotalIncidents[index][priority] < minimumIncidents[priority] {
+				calcTotalIncidents[index+1][priority] += calcTotalIncidents[index][priority]
+				calcTotalIncidents[index][priority] = 0
+				calcSLAMetIncidents[index+1][priority] += calcSLAMetIncidents[index][priority]
+				calcSLAMetIncidents[index][priority] = 0
+			}
+		}
+	}
+
+	areaData := AreaData{Name: area}
+	for _, priority := range []int{Critical, High, Medium, Low} {
+		priorityData := PriorityData{Priority: PriorityNames[priority], Target: SLATarget}
+		for index := 0; index < 6; index++ {
+			monthData := PriorityMonthData{
+				Total:      totalIncidents[index][priority],
+				SLAMet:     slaMetIncidents[index][priority],
+				CalcTotal:  calcTotalIncidents[index][priority],
+				CalcSLAMet: calcSLAMetIncidents[index][priority],
+			}
+			if calcTotalIncidents[index][priority] != 0 {
+				percentage := float64(calcSLAMetIncidents[index][priority]) / float64(calcTotalIncidents[index][priority])
+				monthData.Performance = &percentage
+			}
+			priorityData.Months = append(priorityData.Months, monthData)
+		}
+		areaData.Priorities = append(areaData.Priorities, priorityData)
+	}
+
+	// service availability is only reported for IT
+	if area == "IT" || area == "" {
+		availability := incidents.CalculateITAvailability(month, year)
+		for _, service := range ITServicesNames {
+			areaData.Availability = append(areaData.Availability, ServiceAvailabilityData{
+				Service: service,
+				Target:  AvailabilityTarget,
+				Months:  availability[service],
+			})
+		}
+	}
+
+	return areaData, sixMonthIncidents
+}
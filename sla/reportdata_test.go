@@ -0,0 +1,113 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_calculateAreaData(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := Incidents{
+		{ID: "1", Priority: Critical, CreatedAt: created, SLAReady: true, SLAMet: true},
+		{ID: "2", Priority: Critical, CreatedAt: created, SLAReady: true, SLAMet: false},
+		{ID: "3", Priority: Critical, CreatedAt: created, SLAReady: false},
+		{ID: "4", Priority: High, CreatedAt: created.AddDate(0, -1, 0), SLAReady: true, SLAMet: true},
+		{ID: "5", Priority: Low, CreatedAt: created.AddDate(0, -6, 0), SLAReady: true, SLAMet: true},
+	}
+
+	areaData, sixMonthIncidents := calculateAreaData(incidents, "", 10, 2019, MinimumIncidents{})
+	if len(sixMonthIncidents) != 4 {
+		t.Errorf("Expected 4 incidents in 6 months, got %d", len(sixMonthIncidents))
+	}
+
+	october := areaData.Priorities[Critical].Months[5]
+	if october.Total != 2 || october.SLAMet != 1 {
+		t.Errorf("Expected 2 total and 1 SLA met incidents, got %d and %d", october.Total, october.SLAMet)
+	}
+	if percentage, ok := october.Percentage(); !ok || percentage != 0.5 {
+		t.Errorf("Expected performance of 0.5, got %v (%v)", percentage, ok)
+	}
+
+	september := areaData.Priorities[High].Months[4]
+	if september.Total != 1 || september.SLAMet != 1 {
+		t.Errorf("Expected 1 total and 1 SLA met incident in September, got %d and %d", september.Total, september.SLAMet)
+	}
+
+	if _, ok := areaData.Priorities[Low].Months[0].Percentage(); ok {
+		t.Errorf("Expected no performance without incidents")
+	}
+
+	if len(areaData.Availability) != len(ITServicesNames) {
+		t.Errorf("Expected availability for %d services, got %d", len(ITServicesNames), len(areaData.Availability))
+	}
+}
+
+func Test_calculateAreaDataCarryOver(t *testing.T) {
+	var incidents Incidents
+	// 1 incident in August, met, 2 incidents in September, 1 met
+	incidents = append(incidents, Incident{Priority: Critical, CreatedAt: time.Date(2019, 8, 5, 0, 0, 0, 0, time.UTC), SLAReady: true, SLAMet: true})
+	incidents = append(incidents, Incident{Priority: Critical, CreatedAt: time.Date(2019, 9, 5, 0, 0, 0, 0, time.UTC), SLAReady: true, SLAMet: true})
+	incidents = append(incidents, Incident{Priority: Critical, CreatedAt: time.Date(2019, 9, 6, 0, 0, 0, 0, time.UTC), SLAReady: true, SLAMet: false})
+
+	areaData, _ := calculateAreaData(incidents, "Network", 10, 2019, MinimumIncidents{Critical: 3})
+	months := areaData.Priorities[Critical].Months
+
+	// August does not reach the minimum and is carried over to September
+	if months[3].CalcTotal != 0 || months[3].Total != 1 {
+		t.Errorf("Expected August to be carried over, got calc %d total %d", months[3].CalcTotal, months[3].Total)
+	}
+	if months[4].CalcTotal != 3 || months[4].CalcSLAMet != 2 {
+		t.Errorf("Expected 3 incidents with 2 met in September, got %d and %d", months[4].CalcTotal, months[4].CalcSLAMet)
+	}
+	if percentage, ok := months[4].Percentage(); !ok || percentage != 2.0/3.0 {
+		t.Errorf("Expected performance of 2/3 in September, got %v (%v)", percentage, ok)
+	}
+
+	if len(areaData.Availability) != 0 {
+		t.Errorf("Expected no availability for Network")
+	}
+}
+
+func Test_buildReportData(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := Incidents{
+		{ID: "1", BusinessArea: "IT", ProdCategory2: "foo", CreatedAt: created, SLAReady: true},
+		{ID: "2", BusinessArea: "Network", ProdCategory2: "bar", CreatedAt: created, SLAReady: true},
+		{ID: "3", BusinessArea: "Network", ProdCategory2: "bar", CreatedAt: created, SLAReady: true},
+	}
+
+	data := BuildReportData(incidents, nil, "Sweden", 10, 2019, true, MinimumIncidents{})
+	if len(data.Months) != 6 || data.Months[0].Month != 5 || data.Months[5].Month != 10 {
+		t.Errorf("Expected the months May to October, got %v", data.Months)
+	}
+	if len(data.Areas) != 2 || data.Areas[0].Name != "IT" || data.Areas[1].Name != "Network" {
+		t.Errorf("Expected areas IT and Network, got %v", data.Areas)
+	}
+	if len(data.Incidents) != 3 {
+		t.Errorf("Expected 3 incidents, got %d", len(data.Incidents))
+	}
+	if len(data.ProdCategories) != 2 || data.ProdCategories[0].Name != "bar" {
+		t.Errorf("Expected product category bar first, got %v", data.ProdCategories)
+	}
+}
+
+func Test_NewReportData(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := Incidents{{ID: "1", Country: "Sweden", FlagCorp: true, CreatedAt: created,
+		SolvedAt: created.Add(time.Hour), SLAReady: true}}
+	options := Options{Country: "Sweden", Month: 10, Year: 2019,
+		Adjust: func(incidents Incidents) (Incidents, []string, error) {
+			return incidents, []string{"workbooks disagree on 1"}, nil
+		}}
+
+	data, err := NewReportData(incidents, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Warnings) != 1 || data.Warnings[0] != "workbooks disagree on 1" {
+		t.Errorf("Expected the warning of the adjustments in the report data, got %v", data.Warnings)
+	}
+	if len(data.Incidents) != 1 {
+		t.Errorf("Expected 1 incident in the report, got %d", len(data.Incidents))
+	}
+}
@@ -0,0 +1,197 @@
+package sla
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ruleDateFormat is the format of the From and To dates of a rule
+const ruleDateFormat = "2006-01-02"
+
+// RuleExclusionReason is the start of the reason of an incident excluded by a rule, followed by the rule name
+const RuleExclusionReason = "excluded by rule "
+
+// Rule excludes or reclassifies the incidents that match all its conditions.
+// Action is exclude or reclassify, Set holds the fields to change when reclassifying.
+// From and To optionally limit the rule to incidents created in that period, both inclusive.
+type Rule struct {
+	Name   string
+	Action string
+	Match  []Condition
+	From   string
+	To     string
+	Set    map[string]string
+
+	from time.Time
+	to   time.Time
+}
+
+// Condition compares a field of an incident with a value
+// Op is equals (the default), contains or regex, equals and contains ignore case
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+
+	regex *regexp.Regexp
+}
+
+// incidentFields returns the fields of an incident that can be matched on, as text
+var incidentFields = map[string]func(incident *Incident) string{
+	"id":            func(incident *Incident) string { return incident.ID },
+	"country":       func(incident *Incident) string { return incident.Country },
+	"priority":      func(incident *Incident) string { return PriorityNames[incident.Priority] },
+	"description":   func(incident *Incident) string { return incident.Description },
+	"resolution":    func(incident *Incident) string { return incident.Resolution },
+	"service":       func(incident *Incident) string { return incident.Service },
+	"serviceci":     func(incident *Incident) string { return incident.ServiceCI },
+	"businessarea":  func(incident *Incident) string { return incident.BusinessArea },
+	"prodcategory1": func(incident *Incident) string { return incident.ProdCategory1 },
+	"prodcategory2": func(incident *Incident) string { return incident.ProdCategory2 },
+	"corporate":     func(incident *Incident) string { return strconv.FormatBool(incident.FlagCorp) },
+	"solved":        func(incident *Incident) string { return strconv.FormatBool(!incident.SolvedAt.IsZero()) },
+	"slamet":        func(incident *Incident) string { return strconv.FormatBool(incident.SLAMet) },
+	"exclude":       func(incident *Incident) string { return strconv.FormatBool(incident.Exclude) },
+	"opentime":      func(incident *Incident) string { return strconv.Itoa(incident.OpenTime) },
+	"created":       func(incident *Incident) string { return incident.CreatedAt.Format("2006-01-02 15:04") },
+}
+
+// reclassifyFields sets the fields a reclassify rule can change
+var reclassifyFields = map[string]func(incident *Incident, value string){
+	"priority":      func(incident *Incident, value string) { incident.Priority = StringToPriority(value) },
+	"service":       func(incident *Incident, value string) { incident.Service = value },
+	"serviceci":     func(incident *Incident, value string) { incident.ServiceCI = value },
+	"businessarea":  func(incident *Incident, value string) { incident.BusinessArea = value },
+	"prodcategory1": func(incident *Incident, value string) { incident.ProdCategory1 = value },
+	"prodcategory2": func(incident *Incident, value string) { incident.ProdCategory2 = value },
+}
+
+// Compile checks the rule and prepares the dates and regular expressions
+func (rule *Rule) Compile() error {
+	if rule.Name == "" {
+		return fmt.Errorf("rule without a name")
+	}
+	switch rule.Action {
+	case "exclude":
+	case "reclassify":
+		if len(rule.Set) == 0 {
+			return fmt.Errorf("rule %s: nothing to set", rule.Name)
+		}
+		for field, value := range rule.Set {
+			if _, found := reclassifyFields[strings.ToLower(field)]; !found {
+				return fmt.Errorf("rule %s: field %s cannot be set", rule.Name, field)
+			}
+			if strings.ToLower(field) == "priority" {
+				if _, err := ParsePriority(value); err != nil {
+					return fmt.Errorf("rule %s: %v", rule.Name, err)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown action '%s', use exclude or reclassify", rule.Name, rule.Action)
+	}
+	if len(rule.Match) == 0 {
+		return fmt.Errorf("rule %s: no conditions to match", rule.Name)
+	}
+
+	var err error
+	if rule.From != "" {
+		rule.from, err = time.Parse(ruleDateFormat, rule.From)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid from date %s", rule.Name, rule.From)
+		}
+	}
+	if rule.To != "" {
+		rule.to, err = time.Parse(ruleDateFormat, rule.To)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid to date %s", rule.Name, rule.To)
+		}
+		// the to date is inclusive
+		rule.to = rule.to.AddDate(0, 0, 1)
+	}
+
+	for index := range rule.Match {
+		condition := &rule.Match[index]
+		if _, found := incidentFields[strings.ToLower(condition.Field)]; !found {
+			return fmt.Errorf("rule %s: unknown field %s", rule.Name, condition.Field)
+		}
+		// the rules run before the SLA check and the exclusions, so these are not known yet
+		switch strings.ToLower(condition.Field) {
+		case "slamet", "exclude":
+			return fmt.Errorf("rule %s: field %s is only known after the rules, use it with -where", rule.Name, condition.Field)
+		}
+		switch condition.Op {
+		case "", "equals", "contains":
+		case "regex":
+			condition.regex, err = regexp.Compile(condition.Value)
+			if err != nil {
+				return fmt.Errorf("rule %s: %v", rule.Name, err)
+			}
+		default:
+			return fmt.Errorf("rule %s: unknown op '%s', use equals, contains or regex", rule.Name, condition.Op)
+		}
+	}
+	return nil
+}
+
+// Matches returns whether the incident meets all conditions of the rule
+func (rule *Rule) Matches(incident *Incident) bool {
+	if !rule.from.IsZero() && incident.CreatedAt.Before(rule.from) {
+		return false
+	}
+	if !rule.to.IsZero() && !incident.CreatedAt.Before(rule.to) {
+		return false
+	}
+	for _, condition := range rule.Match {
+		value := incidentFields[strings.ToLower(condition.Field)](incident)
+		switch condition.Op {
+		case "contains":
+			if !strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value)) {
+				return false
+			}
+		case "regex":
+			if !condition.regex.MatchString(value) {
+				return false
+			}
+		default:
+			if !strings.EqualFold(value, condition.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ApplyRules excludes and reclassifies the incidents according to the rules, in the order of the rules.
+// Changed incidents are tagged with the name of the rule, an exclusion also gets it as reason.
+// It returns the number of incidents changed by each rule.
+func ApplyRules(incidents Incidents, rules []Rule) (Incidents, map[string]int) {
+	counts := make(map[string]int)
+	for index := range incidents {
+		incident := &incidents[index]
+		for _, rule := range rules {
+			if incident.Exclude || !rule.Matches(incident) {
+				continue
+			}
+			if rule.Action == "exclude" {
+				incident.Exclude = true
+				incident.SLAReady = false
+				incident.Reason = RuleExclusionReason + rule.Name
+			} else {
+				for field, value := range rule.Set {
+					reclassifyFields[strings.ToLower(field)](incident, value)
+				}
+			}
+			if incident.Rule == "" {
+				incident.Rule = rule.Name
+			} else {
+				incident.Rule += ", " + rule.Name
+			}
+			counts[rule.Name]++
+		}
+	}
+	return incidents, counts
+}
@@ -0,0 +1,60 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_applyRules(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	solved := created.Add(time.Hour)
+	incidents := Incidents{
+		{ID: "1", CreatedAt: created, SolvedAt: solved, SLAReady: true, Resolution: "No Fault Found after checking"},
+		{ID: "2", CreatedAt: created, SolvedAt: solved, SLAReady: true, ServiceCI: "test-server-01"},
+		{ID: "3", CreatedAt: created.AddDate(0, -2, 0), SolvedAt: solved, SLAReady: true, ServiceCI: "test-server-02"},
+		{ID: "4", CreatedAt: created, SolvedAt: solved, SLAReady: true, ProdCategory2: "Radio", BusinessArea: "IT", Priority: High},
+	}
+	rules := []Rule{
+		{Name: "nff", Action: "exclude", Match: []Condition{{Field: "Resolution", Op: "contains", Value: "no fault found"}}},
+		{Name: "test", Action: "exclude", From: "2019-10-01", To: "2019-10-31",
+			Match: []Condition{{Field: "serviceci", Op: "regex", Value: "^test-"}}},
+		{Name: "radio", Action: "reclassify", Match: []Condition{{Field: "prodcategory2", Value: "radio"}},
+			Set: map[string]string{"businessarea": "Network", "priority": "Low"}},
+	}
+	for index := range rules {
+		if err := rules[index].Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	incidents, counts := ApplyRules(incidents, rules)
+	if !incidents[0].Exclude || incidents[0].SLAReady || incidents[0].Rule != "nff" || incidents[0].Reason == "" {
+		t.Errorf("Expected incident 1 to be excluded by nff, got %+v", incidents[0])
+	}
+	if !incidents[1].Exclude || incidents[1].Rule != "test" {
+		t.Errorf("Expected incident 2 to be excluded by test, got %+v", incidents[1])
+	}
+	if incidents[2].Exclude {
+		t.Errorf("Expected incident 3 outside the dates of the rule to be kept")
+	}
+	if incidents[3].BusinessArea != "Network" || incidents[3].Priority != Low || incidents[3].Rule != "radio" {
+		t.Errorf("Expected incident 4 to be reclassified, got %+v", incidents[3])
+	}
+	if counts["nff"] != 1 || counts["test"] != 1 || counts["radio"] != 1 {
+		t.Errorf("Expected each rule to change 1 incident, got %v", counts)
+	}
+
+	invalid := []Rule{
+		{Name: "action", Action: "delete", Match: []Condition{{Field: "id", Value: "1"}}},
+		{Name: "field", Action: "exclude", Match: []Condition{{Field: "colour", Value: "red"}}},
+		{Name: "slamet", Action: "exclude", Match: []Condition{{Field: "SLAMet", Value: "false"}}},
+		{Name: "excluded", Action: "exclude", Match: []Condition{{Field: "exclude", Value: "true"}}},
+		{Name: "regex", Action: "exclude", Match: []Condition{{Field: "id", Op: "regex", Value: "("}}},
+		{Name: "set", Action: "reclassify", Match: []Condition{{Field: "id", Value: "1"}}, Set: map[string]string{"id": "2"}},
+	}
+	for _, rule := range invalid {
+		if err := rule.Compile(); err == nil {
+			t.Errorf("Expected an error for rule %s", rule.Name)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package sla
 
 import (
 	"time"
@@ -21,7 +21,7 @@ type ReportPeriod struct {
 // this gives number of days in the month
 // returns the result in a ServiceAvailability map
 func getMinutesInMonth(month int, year int) int {
-	month, year = getNextMonth(month, year)
+	month, year = NextMonth(month, year)
 	return time.Date(year, time.Month(month), 0, 0, 0, 0, 0, time.UTC).Day() * 24 * 60
 }
 
@@ -31,7 +31,7 @@ func calculateSA(incidents Incidents, services []string, period ReportPeriod) Se
 	month := period.startMonth
 	year := period.startYear
 
-	criticalIncidents := incidents.filterByPriority(Critical)
+	criticalIncidents := incidents.FilterByPriority(Critical)
 
 	result := make(ServiceAvailability)
 
@@ -39,31 +39,16 @@ func calculateSA(incidents Incidents, services []string, period ReportPeriod) Se
 	for {
 		totMinutes := getMinutesInMonth(month, year)
 
-		monthIncidents := criticalIncidents.filterByMonthYear(month, year)
-
-		// get incidents from previous months to see if there're incidents that roll over into the current month
-		prevMonth, prevYear := getPreviousMonth(month, year)
-		prevMonthIncidents := criticalIncidents.filterByMonthYear(prevMonth, prevYear)
-		for _, incident := range prevMonthIncidents {
-			if incident.isResolvedInMonthYear(month, year) {
-				monthIncidents = append(monthIncidents, incident)
-			}
-		}
-
 		for _, service := range services {
 			outageMinutes := 0
 
-			// go through incidents for a service in this month to get the outage minutes
-			//TODO: use only outage minutes in this month from last months incidents
+			// go through incidents for a service to get the outage minutes in this month,
+			// incidents that roll over from the month before only count with their minutes in this month
 			//TODO: count each outage minute for a given service only once (overlapping outages)
-			serviceIncidents := monthIncidents.filterByService(service)
+			serviceIncidents := criticalIncidents.FilterByService(service)
 			for _, incident := range serviceIncidents {
 				if incident.SLAReady {
-					if incident.CorrectedTime == "" {
-						outageMinutes += incident.OpenTime
-					} else {
-						outageMinutes += int(incident.CorrectedOpenTime.Minutes())
-					}
+					outageMinutes += incident.getOutageMinutesInMonth(month, year)
 				}
 			}
 
@@ -76,7 +61,7 @@ func calculateSA(incidents Incidents, services []string, period ReportPeriod) Se
 		if month == period.endMonth && year == period.endYear {
 			break
 		}
-		month, year = getNextMonth(month, year)
+		month, year = NextMonth(month, year)
 	}
 
 	return result
@@ -0,0 +1,34 @@
+package sla
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_calculateSA(t *testing.T) {
+	// an outage of 2 hours from 23:00 on the last day of September
+	solved := time.Date(2019, 10, 1, 1, 0, 0, 0, time.UTC)
+	incidents := Incidents{
+		{ID: "1", Priority: Critical, Service: "CRM", CreatedAt: solved.Add(-2 * time.Hour), SolvedAt: solved,
+			OpenTime: 120, SLAReady: true},
+		{ID: "2", Priority: High, Service: "CRM", CreatedAt: solved, SolvedAt: solved.Add(time.Hour),
+			OpenTime: 60, SLAReady: true},
+	}
+	period := ReportPeriod{startMonth: 8, startYear: 2019, endMonth: 11, endYear: 2019}
+
+	availability := calculateSA(incidents, []string{"CRM"}, period)["CRM"]
+	expected := []float64{1, 1, 1, 1}
+	for index, month := range []int{9, 10} {
+		minutes := float64(getMinutesInMonth(month, 2019))
+		expected[index+1] = (minutes - 60) / minutes
+	}
+	if len(availability) != len(expected) {
+		t.Fatalf("Expected the availability of 4 months, got %v", availability)
+	}
+	for index := range expected {
+		if availability[index] != expected[index] {
+			t.Errorf("Expected the availability %v, got %v", expected, availability)
+			break
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package sla
+
+import (
+	"fmt"
+	"time"
+)
+
+// SLA struct in the configuration file
+// Either Hours or Days has a value
+type SLA struct {
+	Priority string
+	Hours    int
+	Days     int
+}
+
+// SLAEntry is a struct describing SLA for a given priority,
+// Either hours or days has a value, the other defaults to 0.
+// days means business days
+type SLAEntry struct {
+	hours int
+	days  int
+}
+
+// StringToPriority converts a string describing the priority to int
+func StringToPriority(str string) int {
+	switch str {
+	case "Critical":
+		return Critical
+	case "High":
+		return High
+	case "Medium":
+		return Medium
+	case "Low":
+		return Low
+	default:
+		return Low
+	}
+}
+
+// ParsePriority converts the name of a priority, unlike StringToPriority unknown names are an error
+func ParsePriority(name string) (int, error) {
+	for id, priorityName := range PriorityNames {
+		if priorityName == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown priority: %s", name)
+}
+
+// PriorityToString converts priority as an int to a string describing the name
+//func PriorityToString(priority int) string {
+//	return PriorityNames[priority]
+//}
+
+// ParseSLAConfig takes an array of SLA from the configuration and converts
+// it to an array of SLAEntry structs
+func ParseSLAConfig(slaConfig []SLA) [4]SLAEntry {
+	slaSet := [4]SLAEntry{}
+	for _, slaConfigEntry := range slaConfig {
+		id := StringToPriority(slaConfigEntry.Priority)
+		slaSet[id].days = slaConfigEntry.Days
+		slaSet[id].hours = slaConfigEntry.Hours
+	}
+	return slaSet
+}
+
+// CheckIncidentsAgainstSLA sets the due time and whether the incidents met the SLA, with and without their adjustments.
+// It returns an IncidentError for a corrected time that is not a duration.
+func CheckIncidentsAgainstSLA(incidents []Incident, slaSet [4]SLAEntry) ([]Incident, error) {
+	var slaIncidents []Incident
+	for _, incident := range incidents {
+		var err error
+		incident.SLAMet, err = checkSLA(incident, slaSet)
+		if err != nil {
+			return nil, err
+		}
+
+		// the outcome as it would be without exclusion and corrected time, for the audit trail
+		uncorrected := incident
+		uncorrected.SLAReady = !incident.SolvedAt.IsZero()
+		uncorrected.CorrectedTime = ""
+		incident.UncorrectedSLAMet, _ = checkSLA(uncorrected, slaSet)
+		incident.Due, _ = slaSet[incident.Priority].dueTime(incident.CreatedAt)
+
+		slaIncidents = append(slaIncidents, incident)
+	}
+	return slaIncidents, nil
+}
+
+func checkSLA(incident Incident, slaSet [4]SLAEntry) (bool, error) {
+
+	// only process if the incident is solved
+	if !incident.SLAReady {
+		return false, nil
+	}
+	if slaSet[incident.Priority].days == 0 {
+		return checkSLAHours(incident, slaSet[incident.Priority].hours), nil
+	}
+	return checkSLABusinessDays(incident, slaSet[incident.Priority].days)
+
+}
+
+func checkSLAHours(incident Incident, hours int) bool {
+	target, _ := SLAEntry{hours: hours}.dueTime(incident.CreatedAt)
+	if incident.CorrectedTime != "" {
+		incident.CorrectedSolved = incident.CreatedAt.Add(incident.CorrectedOpenTime)
+		return target.After(incident.CorrectedSolved)
+	}
+	return target.After(incident.SolvedAt)
+}
+
+func checkSLABusinessDays(incident Incident, days int) (bool, error) {
+	targetTime, _ := SLAEntry{days: days}.dueTime(incident.CreatedAt)
+
+	if incident.CorrectedTime != "" {
+		correctedDuration, err := time.ParseDuration(incident.CorrectedTime)
+		if err != nil {
+			return false, &IncidentError{ID: incident.ID, Err: fmt.Errorf("parsing corrected time: %w", err)}
+		}
+		incident.CorrectedSolved = incident.CreatedAt.Add(correctedDuration)
+		return targetTime.After(incident.CorrectedSolved), nil
+	}
+	return targetTime.After(incident.SolvedAt), nil
+}
+
+// dueTime returns the time an incident created at the given time has to be solved before:
+// the hours after creation, or the end of the last of the business days following the day of creation.
+// For business days it also returns the weekend days that were skipped.
+func (entry SLAEntry) dueTime(created time.Time) (time.Time, []time.Time) {
+	if entry.days == 0 {
+		duration, _ := time.ParseDuration(fmt.Sprintf("%dh", entry.hours))
+		return created.Add(duration), nil
+	}
+
+	// get time at start of day at CreatedAt
+	targetTime := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+	oneDay, _ := time.ParseDuration("24h")
+	var skipped []time.Time
+	for days := entry.days; days >= 0; {
+		targetTime = targetTime.Add(oneDay)
+		if isWeekDay(targetTime) {
+			days--
+		} else {
+			skipped = append(skipped, targetTime)
+		}
+	}
+	return targetTime, skipped
+}
+
+func isWeekDay(moment time.Time) bool {
+	return moment.Weekday() != time.Saturday && moment.Weekday() != time.Sunday
+}
@@ -1,4 +1,4 @@
-package main
+package sla
 
 import (
 	"testing"
@@ -34,19 +34,19 @@ func Test_checkSLABusinessDays(t *testing.T) {
 
 	// start on Sunday, solve on Sunday
 	incident := Incident{CreatedAt: timeStart, SolvedAt: timePlus12}
-	if !checkSLABusinessDays(incident, 1) {
+	if met, _ := checkSLABusinessDays(incident, 1); !met {
 		t.Errorf("checkSLABusinessDays created:%v solved:%v, SLA=1d", incident.CreatedAt, incident.SolvedAt)
 	}
 
 	// start on Sunday, solve on Monday
 	incident.SolvedAt = timePlus24
-	if !checkSLABusinessDays(incident, 1) {
+	if met, _ := checkSLABusinessDays(incident, 1); !met {
 		t.Errorf("checkSLABusinessDays created:%v solved:%v, SLA=1d", incident.CreatedAt, incident.SolvedAt)
 	}
 
 	// start on Sunday, solve on Tuesday
 	incident.SolvedAt = timePlus48
-	if checkSLABusinessDays(incident, 1) {
+	if met, _ := checkSLABusinessDays(incident, 1); met {
 		t.Errorf("checkSLABusinessDays created:%v solved:%v, SLA=1d", incident.CreatedAt, incident.SolvedAt)
 	}
 
@@ -60,13 +60,13 @@ func Test_checkSLABusinessDays(t *testing.T) {
 	incident.SolvedAt = timePlus12
 
 	// start on Monday, solve on Monday
-	if !checkSLABusinessDays(incident, 1) {
+	if met, _ := checkSLABusinessDays(incident, 1); !met {
 		t.Errorf("checkSLABusinessDays created:%v solved:%v, SLA=1d", incident.CreatedAt, incident.SolvedAt)
 	}
 
 	// start on Monday, solve on Tuesday
 	incident.SolvedAt = timePlus24
-	if !checkSLABusinessDays(incident, 1) {
+	if met, _ := checkSLABusinessDays(incident, 1); !met {
 		t.Errorf("checkSLABusinessDays created:%v solved:%v, SLA=1d", incident.CreatedAt, incident.SolvedAt)
 	}
 }
@@ -97,3 +97,40 @@ func Test_isWeekDay(t *testing.T) {
 	}
 
 }
+
+func Test_checkIncidentsAgainstSLAUncorrected(t *testing.T) {
+	timeStart := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	slaSet := ParseSLAConfig([]SLA{{Priority: "Critical", Hours: 2}})
+	incidents := Incidents{
+		// solved in 4h, corrected to 1h
+		{ID: "1", Priority: Critical, CreatedAt: timeStart, SolvedAt: timeStart.Add(4 * time.Hour), SLAReady: true,
+			CorrectedTime: "1h", CorrectedOpenTime: time.Hour},
+		// solved in 1h, excluded
+		{ID: "2", Priority: Critical, CreatedAt: timeStart, SolvedAt: timeStart.Add(time.Hour), Exclude: true},
+	}
+
+	incidents, err := CheckIncidentsAgainstSLA(incidents, slaSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !incidents[0].SLAMet || incidents[0].UncorrectedSLAMet {
+		t.Errorf("Expected SLA met only after correction, got %v and %v", incidents[0].SLAMet, incidents[0].UncorrectedSLAMet)
+	}
+	if incidents[1].SLAMet || !incidents[1].UncorrectedSLAMet {
+		t.Errorf("Expected SLA met only without exclusion, got %v and %v", incidents[1].SLAMet, incidents[1].UncorrectedSLAMet)
+	}
+}
+
+func Test_checkIncidentsAgainstSLAInvalid(t *testing.T) {
+	timeStart := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	slaSet := ParseSLAConfig([]SLA{{Priority: "Low", Days: 2}})
+	incidents := Incidents{
+		{ID: "1", Priority: Low, CreatedAt: timeStart, SolvedAt: timeStart.Add(time.Hour), SLAReady: true, CorrectedTime: "3 hours"},
+	}
+
+	_, err := CheckIncidentsAgainstSLA(incidents, slaSet)
+	incidentErr, ok := err.(*IncidentError)
+	if !ok || incidentErr.ID != "1" {
+		t.Errorf("Expected an error for incident 1, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package sla
+
+import (
+	"strings"
+	"time"
+)
+
+// Trace explains how the SLA outcome and the service availability of an incident were computed
+type Trace struct {
+	Hours       int         // the SLA of the priority in hours, 0 if it is in business days
+	Days        int         // the SLA of the priority in business days, 0 if it is in hours
+	Due         time.Time   // the incident meets the SLA if it is solved before this time
+	SkippedDays []time.Time // the weekend days skipped walking the business days
+	Solved      time.Time   // the time compared with the due time, the creation plus the corrected time if corrected
+	Counted     bool        // the incident counts for the SLA performance: it is solved and not excluded
+	Met         bool
+
+	Availability  bool // the incident counts for the availability of its service
+	OutageMinutes []MonthMinutes
+}
+
+// MonthMinutes are the outage minutes of an incident in a month
+type MonthMinutes struct {
+	Month   int
+	Year    int
+	Minutes int
+}
+
+// TraceIncident computes the SLA outcome of a prepared incident step by step, with the same rules as
+// CheckIncidentsAgainstSLA, and the outage minutes it contributes to each month.
+// splitArea tells whether the report splits the business areas, the availability is then only reported for IT.
+func TraceIncident(incident Incident, slaSet [4]SLAEntry, splitArea bool) (Trace, error) {
+	entry := slaSet[incident.Priority]
+	trace := Trace{Hours: entry.hours, Days: entry.days, Counted: incident.SLAReady}
+	trace.Due, trace.SkippedDays = entry.dueTime(incident.CreatedAt)
+
+	var err error
+	trace.Met, err = checkSLA(incident, slaSet)
+	if err != nil {
+		return trace, err
+	}
+	trace.Solved = incident.SolvedAt
+	if incident.CorrectedTime != "" {
+		correctedDuration, err := time.ParseDuration(incident.CorrectedTime)
+		if err != nil {
+			return trace, &IncidentError{ID: incident.ID, Err: err}
+		}
+		trace.Solved = incident.CreatedAt.Add(correctedDuration)
+	}
+
+	if !incident.SLAReady {
+		return trace, nil
+	}
+	// the availability is computed for the IT services from their Critical incidents
+	if incident.Priority == Critical && (!splitArea || incident.BusinessArea == "IT") {
+		for _, service := range ITServicesNames {
+			// compared like calculateSA does, ignoring case
+			if strings.EqualFold(service, incident.Service) {
+				trace.Availability = true
+			}
+		}
+	}
+
+	// walk the months from the start to the end of the outage
+	start, end := incident.outage()
+	month, year := int(start.Month()), start.Year()
+	for !time.Date(year, time.Month(month), 1, 0, 0, 0, 0, incident.CreatedAt.Location()).After(end) {
+		if minutes := incident.getOutageMinutesInMonth(month, year); minutes > 0 {
+			trace.OutageMinutes = append(trace.OutageMinutes, MonthMinutes{Month: month, Year: year, Minutes: minutes})
+		}
+		month, year = NextMonth(month, year)
+	}
+	return trace, nil
+}
@@ -0,0 +1,62 @@
+package sla
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_TraceIncident(t *testing.T) {
+	slaSet := ParseSLAConfig([]SLA{{Priority: "Critical", Hours: 4}, {Priority: "Medium", Days: 2}})
+
+	// created on a Friday, the 2 business days are Monday and Tuesday
+	created := time.Date(2019, 10, 4, 9, 0, 0, 0, time.UTC)
+	incident := Incident{ID: "1", Priority: Medium, CreatedAt: created, SolvedAt: created.Add(96 * time.Hour),
+		OpenTime: 96 * 60, SLAReady: true}
+	trace, err := TraceIncident(incident, slaSet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2019, 10, 9, 0, 0, 0, 0, time.UTC); !trace.Due.Equal(want) {
+		t.Errorf("Expected due %v, got %v", want, trace.Due)
+	}
+	skipped := []time.Time{time.Date(2019, 10, 5, 0, 0, 0, 0, time.UTC), time.Date(2019, 10, 6, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(trace.SkippedDays, skipped) {
+		t.Errorf("Expected the weekend to be skipped, got %v", trace.SkippedDays)
+	}
+	if !trace.Met || !trace.Counted || trace.Availability {
+		t.Errorf("Expected a counted, met incident that does not count for the availability, got %+v", trace)
+	}
+
+	// a Critical incident of an IT service rolling over into November, with a corrected time
+	created = time.Date(2019, 10, 31, 23, 0, 0, 0, time.UTC)
+	incident = Incident{ID: "2", Priority: Critical, Service: ITServicesNames[0], CreatedAt: created,
+		SolvedAt: created.Add(10 * time.Hour), OpenTime: 600, SLAReady: true,
+		CorrectedTime: "3h", CorrectedOpenTime: 3 * time.Hour}
+	trace, err = TraceIncident(incident, slaSet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trace.Met || !trace.Solved.Equal(created.Add(3*time.Hour)) {
+		t.Errorf("Expected the corrected time to meet the SLA, got %+v", trace)
+	}
+	expected := []MonthMinutes{{Month: 10, Year: 2019, Minutes: 60}, {Month: 11, Year: 2019, Minutes: 120}}
+	if !trace.Availability || !reflect.DeepEqual(trace.OutageMinutes, expected) {
+		t.Errorf("Expected the outage minutes %v, got %v", expected, trace.OutageMinutes)
+	}
+
+	// the service counts for the availability whatever its case, like in the report
+	for _, service := range []string{strings.ToUpper(ITServicesNames[0]), strings.ToLower(ITServicesNames[0])} {
+		incident.Service = service
+		trace, err = TraceIncident(incident, slaSet, false)
+		if err != nil || !trace.Availability {
+			t.Errorf("Expected service %s to count for the availability, got %+v", service, trace)
+		}
+	}
+
+	incident.CorrectedTime = "soon"
+	if _, err := TraceIncident(incident, slaSet, false); err == nil {
+		t.Errorf("Expected an error for an invalid corrected time")
+	}
+}
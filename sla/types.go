@@ -1,4 +1,4 @@
-package main
+package sla
 
 // Priority of incidents, int
 const (
@@ -8,6 +8,12 @@ const (
 	Low
 )
 
+// SLATarget is the minimum SLA performance, lower values are marked red
+const SLATarget = 0.8
+
+// AvailabilityTarget is the minimum service availability, lower values are marked red
+const AvailabilityTarget = 0.995
+
 // PriorityNames is an array continaing strings describing the priority
 var PriorityNames = []string{"Critical", "High", "Medium", "Low"}
 
@@ -0,0 +1,419 @@
+package sla
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// kinds of tokens in a where expression
+const (
+	whereEnd = iota
+	whereWord
+	whereString
+	whereOperator
+	wherePunctuation
+)
+
+// whereOperators are the comparisons, ~ and !~ match a regular expression ignoring case
+var whereOperators = []string{"==", "!=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// whereFlags are the fields that can be used without a comparison, as in "not slamet"
+var whereFlags = map[string]bool{"corporate": true, "solved": true, "slamet": true, "exclude": true}
+
+// Expression is a parsed where expression, it tells whether an incident matches
+type Expression interface {
+	Matches(incident *Incident) bool
+}
+
+// WhereError is an error in a where expression, pointing at the offending token
+type WhereError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func (err *WhereError) Error() string {
+	return fmt.Sprintf("%s at position %d\n  %s\n  %s^", err.Message, err.Position+1, err.Expression,
+		strings.Repeat(" ", err.Position))
+}
+
+type whereToken struct {
+	kind     int
+	text     string
+	position int
+}
+
+type whereParser struct {
+	expression string
+	tokens     []whereToken
+	current    int
+}
+
+type whereAnd struct {
+	left  Expression
+	right Expression
+}
+
+type whereOr struct {
+	left  Expression
+	right Expression
+}
+
+type whereNot struct {
+	expression Expression
+}
+
+type whereFlag struct {
+	field func(incident *Incident) string
+}
+
+type whereCompare struct {
+	field    func(incident *Incident) string
+	operator string
+	value    string
+	regex    *regexp.Regexp
+	priority bool // the values are priorities, ordered by severity
+}
+
+type whereIn struct {
+	field  func(incident *Incident) string
+	values []string
+}
+
+// ParseWhere parses an expression like: priority in (Critical,High) and service ~ "CRM" and not slamet
+// the fields are the ones the rules match on, keywords and values ignore case
+func ParseWhere(expression string) (Expression, error) {
+	tokens, err := tokenizeWhere(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := whereParser{expression: expression, tokens: tokens}
+	result, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != whereEnd {
+		return nil, parser.errorAt(token, fmt.Sprintf("unexpected '%s'", token.text))
+	}
+	return result, nil
+}
+
+func tokenizeWhere(expression string) ([]whereToken, error) {
+	var tokens []whereToken
+	position := 0
+	for position < len(expression) {
+		c := expression[position]
+		switch {
+		case c == ' ' || c == '\t':
+			position++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, whereToken{wherePunctuation, string(c), position})
+			position++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[position+1:], c)
+			if end == -1 {
+				return nil, &WhereError{expression, position, "unterminated string"}
+			}
+			tokens = append(tokens, whereToken{whereString, expression[position+1 : position+1+end], position})
+			position += end + 2
+		case strings.IndexByte("=!~<>", c) != -1:
+			operator := ""
+			for _, candidate := range whereOperators {
+				if strings.HasPrefix(expression[position:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator == "" {
+				return nil, &WhereError{expression, position, fmt.Sprintf("unknown operator '%c'", c)}
+			}
+			tokens = append(tokens, whereToken{whereOperator, operator, position})
+			position += len(operator)
+		default:
+			start := position
+			for position < len(expression) && isWhereWordChar(expression[position]) {
+				position++
+			}
+			if start == position {
+				return nil, &WhereError{expression, position, fmt.Sprintf("unexpected character '%c'", c)}
+			}
+			tokens = append(tokens, whereToken{whereWord, expression[start:position], start})
+		}
+	}
+	return append(tokens, whereToken{whereEnd, "", len(expression)}), nil
+}
+
+func isWhereWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_-.:/", c) != -1 || c >= 0x80
+}
+
+func (parser *whereParser) peek() whereToken {
+	return parser.tokens[parser.current]
+}
+
+func (parser *whereParser) next() whereToken {
+	token := parser.tokens[parser.current]
+	if token.kind != whereEnd {
+		parser.current++
+	}
+	return token
+}
+
+func (parser *whereParser) errorAt(token whereToken, message string) error {
+	if token.kind == whereEnd {
+		message = "unexpected end of expression, " + message
+	}
+	return &WhereError{parser.expression, token.position, message}
+}
+
+func isWhereKeyword(token whereToken, keyword string) bool {
+	return token.kind == whereWord && strings.EqualFold(token.text, keyword)
+}
+
+func isWherePunctuation(token whereToken, punctuation string) bool {
+	return token.kind == wherePunctuation && token.text == punctuation
+}
+
+func (parser *whereParser) parseOr() (Expression, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for isWhereKeyword(parser.peek(), "or") {
+		parser.next()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereOr{left, right}
+	}
+	return left, nil
+}
+
+func (parser *whereParser) parseAnd() (Expression, error) {
+	left, err := parser.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for isWhereKeyword(parser.peek(), "and") {
+		parser.next()
+		right, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereAnd{left, right}
+	}
+	return left, nil
+}
+
+func (parser *whereParser) parseNot() (Expression, error) {
+	if isWhereKeyword(parser.peek(), "not") {
+		parser.next()
+		expression, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &whereNot{expression}, nil
+	}
+	return parser.parseCondition()
+}
+
+// parseCondition parses a condition on a field or an expression between parentheses
+func (parser *whereParser) parseCondition() (Expression, error) {
+	token := parser.next()
+	if isWherePunctuation(token, "(") {
+		expression, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := parser.next(); !isWherePunctuation(closing, ")") {
+			return nil, parser.errorAt(closing, "expected ')'")
+		}
+		return expression, nil
+	}
+	if token.kind != whereWord || isWhereKeyword(token, "and") || isWhereKeyword(token, "or") ||
+		isWhereKeyword(token, "in") {
+		return nil, parser.errorAt(token, "expected a field")
+	}
+	name := strings.ToLower(token.text)
+	field, found := incidentFields[name]
+	if !found {
+		return nil, parser.errorAt(token, fmt.Sprintf("unknown field '%s'", token.text))
+	}
+
+	next := parser.peek()
+	switch {
+	case next.kind == whereOperator:
+		parser.next()
+		value := parser.next()
+		if value.kind != whereWord && value.kind != whereString {
+			return nil, parser.errorAt(value, fmt.Sprintf("expected a value after '%s'", next.text))
+		}
+		compare := &whereCompare{field: field, operator: next.text, value: value.text}
+		switch next.text {
+		case "~", "!~":
+			var err error
+			compare.regex, err = regexp.Compile("(?i)" + value.text)
+			if err != nil {
+				return nil, parser.errorAt(value, fmt.Sprintf("invalid regular expression: %v", err))
+			}
+		default:
+			if err := parser.checkValue(name, value); err != nil {
+				return nil, err
+			}
+			compare.priority = name == "priority"
+		}
+		return compare, nil
+
+	case isWhereKeyword(next, "in"):
+		parser.next()
+		if open := parser.next(); !isWherePunctuation(open, "(") {
+			return nil, parser.errorAt(open, "expected '(' after in")
+		}
+		in := &whereIn{field: field}
+		for {
+			value := parser.next()
+			if value.kind != whereWord && value.kind != whereString {
+				return nil, parser.errorAt(value, "expected a value")
+			}
+			if err := parser.checkValue(name, value); err != nil {
+				return nil, err
+			}
+			in.values = append(in.values, value.text)
+			separator := parser.next()
+			if isWherePunctuation(separator, ")") {
+				return in, nil
+			}
+			if !isWherePunctuation(separator, ",") {
+				return nil, parser.errorAt(separator, "expected ',' or ')'")
+			}
+		}
+
+	default:
+		if !whereFlags[name] {
+			return nil, parser.errorAt(next, fmt.Sprintf("expected a comparison for field '%s'", token.text))
+		}
+		return &whereFlag{field}, nil
+	}
+}
+
+// checkValue catches misspelled priorities, which would silently match nothing
+func (parser *whereParser) checkValue(field string, value whereToken) error {
+	if field != "priority" {
+		return nil
+	}
+	for _, name := range PriorityNames {
+		if strings.EqualFold(name, value.text) {
+			return nil
+		}
+	}
+	return parser.errorAt(value, fmt.Sprintf("unknown priority '%s', use %s", value.text, strings.Join(PriorityNames, ", ")))
+}
+
+func (expression *whereAnd) Matches(incident *Incident) bool {
+	return expression.left.Matches(incident) && expression.right.Matches(incident)
+}
+
+func (expression *whereOr) Matches(incident *Incident) bool {
+	return expression.left.Matches(incident) || expression.right.Matches(incident)
+}
+
+func (expression *whereNot) Matches(incident *Incident) bool {
+	return !expression.expression.Matches(incident)
+}
+
+func (expression *whereFlag) Matches(incident *Incident) bool {
+	return expression.field(incident) == "true"
+}
+
+func (expression *whereIn) Matches(incident *Incident) bool {
+	value := expression.field(incident)
+	for _, candidate := range expression.values {
+		if strings.EqualFold(value, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func (expression *whereCompare) Matches(incident *Incident) bool {
+	value := expression.field(incident)
+	switch expression.operator {
+	case "=", "==":
+		return strings.EqualFold(value, expression.value)
+	case "!=":
+		return !strings.EqualFold(value, expression.value)
+	case "~":
+		return expression.regex.MatchString(value)
+	case "!~":
+		return !expression.regex.MatchString(value)
+	}
+
+	var result int
+	if expression.priority {
+		result = comparePriorities(value, expression.value)
+	} else {
+		result = compareWhereValues(value, expression.value)
+	}
+	switch expression.operator {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	default:
+		return result >= 0
+	}
+}
+
+// compareWhereValues compares numbers by value and everything else as text,
+// the created time is formatted so it sorts as text
+func compareWhereValues(a string, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// comparePriorities compares priorities by severity, so Critical is the greatest and Low the least
+func comparePriorities(a string, b string) int {
+	rank := func(name string) int {
+		for index, priorityName := range PriorityNames {
+			if strings.EqualFold(priorityName, name) {
+				return index
+			}
+		}
+		return len(PriorityNames)
+	}
+	x, y := rank(a), rank(b)
+	switch {
+	case x > y:
+		return -1
+	case x < y:
+		return 1
+	}
+	return 0
+}
+
+// FilterWhere returns the incidents that match the where expression
+func (incidents *Incidents) FilterWhere(expression Expression) Incidents {
+	var result []Incident
+	for index := range *incidents {
+		if expression.Matches(&(*incidents)[index]) {
+			result = append(result, (*incidents)[index])
+		}
+	}
+	return result
+}
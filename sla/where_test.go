@@ -0,0 +1,71 @@
+package sla
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseWhere(t *testing.T) {
+	created := time.Date(2019, 10, 7, 9, 0, 0, 0, time.UTC)
+	incidents := Incidents{
+		{ID: "1", Priority: Critical, Service: "CRM", SLAMet: false, OpenTime: 300, CreatedAt: created},
+		{ID: "2", Priority: High, Service: "CRM Online", SLAMet: true, OpenTime: 60, CreatedAt: created},
+		{ID: "3", Priority: High, Service: "Billing", SLAMet: false, OpenTime: 90, CreatedAt: created.AddDate(0, 1, 0)},
+		{ID: "4", Priority: Low, Service: "crm", SLAMet: false, OpenTime: 30, CreatedAt: created},
+	}
+
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{`priority in (Critical,High) and service ~ "CRM" and not slamet`, "1"},
+		{`service = crm`, "1,4"},
+		{`service != CRM and (priority = low or opentime >= 90)`, "3"},
+		{`opentime > 60 or slamet`, "1,2,3"},
+		{`created < '2019-11-01' and service !~ online`, "1,4"},
+		{`not (slamet or priority == "low")`, "1,3"},
+		{`priority >= high`, "1,2,3"},
+		{`priority < High`, "4"},
+		{`priority > medium and priority <= high`, "2,3"},
+	}
+	for _, test := range tests {
+		expression, err := ParseWhere(test.expression)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %s: %v", test.expression, err)
+			continue
+		}
+		var ids []string
+		for _, incident := range incidents.FilterWhere(expression) {
+			ids = append(ids, incident.ID)
+		}
+		if strings.Join(ids, ",") != test.want {
+			t.Errorf("%s: expected %s, got %v", test.expression, test.want, ids)
+		}
+	}
+
+	errors := []struct {
+		expression string
+		position   int
+	}{
+		{`priority in (Critical,High) and servce ~ "CRM"`, 32},
+		{`priority = Urgent`, 11},
+		{`priority > Urgent`, 11},
+		{`service ~ "CRM`, 10},
+		{`(slamet or solved`, 17},
+		{`service and slamet`, 8},
+		{`slamet slamet`, 7},
+		{`service ~ "("`, 10},
+	}
+	for _, test := range errors {
+		_, err := ParseWhere(test.expression)
+		whereErr, ok := err.(*WhereError)
+		if !ok {
+			t.Errorf("%s: expected a where error, got %v", test.expression, err)
+			continue
+		}
+		if whereErr.Position != test.position {
+			t.Errorf("%s: expected the error at %d, got %d: %v", test.expression, test.position, whereErr.Position, err)
+		}
+	}
+}
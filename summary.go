@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// the number of product categories listed in the summary
+const summaryTopProdCategories = 5
+
+// breachMarker is appended to values below their target
+const breachMarker = " ❌"
+
+// writeSummary writes a compact Markdown digest of the report month, to be pasted in a chat or mail
+// it compares the report month with the month before
+func writeSummary(w io.Writer, data *sla.ReportData) {
+	current := len(data.Months) - 1
+	previous := current - 1
+	currentName := data.Months[current].Name
+	previousName := data.Months[previous].Name
+
+	fmt.Fprintf(w, "# Incident summary %s %s %d\n", data.Country, currentName, data.Year)
+
+	for _, area := range data.Areas {
+		areaName := ""
+		if area.Name != "" {
+			areaName = " " + area.Name
+		}
+
+		fmt.Fprintf(w, "\n## Incidents%s\n\n", areaName)
+		fmt.Fprintf(w, "| Priority | %s | %s | Change |\n", currentName, previousName)
+		fmt.Fprintf(w, "| --- | ---: | ---: | ---: |\n")
+		for _, priority := range area.Priorities {
+			currentTotal := priority.Months[current].Total
+			previousTotal := priority.Months[previous].Total
+			fmt.Fprintf(w, "| %s | %d | %d | %+d |\n", priority.Priority, currentTotal, previousTotal, currentTotal-previousTotal)
+		}
+
+		fmt.Fprintf(w, "\n## SLA performance%s\n\n", areaName)
+		fmt.Fprintf(w, "| Priority | Target | %s | %s | Change |\n", currentName, previousName)
+		fmt.Fprintf(w, "| --- | ---: | ---: | ---: | ---: |\n")
+		for _, priority := range area.Priorities {
+			currentPerformance, currentOk := priority.Months[current].Percentage()
+			previousPerformance, previousOk := priority.Months[previous].Percentage()
+			change := "-"
+			if currentOk && previousOk {
+				change = fmt.Sprintf("%+.1f pp", (currentPerformance-previousPerformance)*100)
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", priority.Priority, formatPercentage(priority.Target, 0),
+				formatSummaryPercentage(priority.Months[current], priority.Target),
+				formatSummaryPercentage(priority.Months[previous], priority.Target), change)
+		}
+
+		if len(area.Availability) > 0 {
+			fmt.Fprintf(w, "\n## Availability below target%s\n\n", areaName)
+			var below []string
+			for _, service := range area.Availability {
+				value := service.Months[current]
+				if value < service.Target {
+					below = append(below, fmt.Sprintf("- %s: %s (target %s)%s", service.Service,
+						formatPercentage(value, 2), formatPercentage(service.Target, 2), breachMarker))
+				}
+			}
+			if len(below) == 0 {
+				fmt.Fprintf(w, "All services met the target of %s.\n", formatPercentage(sla.AvailabilityTarget, 2))
+			} else {
+				fmt.Fprintln(w, strings.Join(below, "\n"))
+			}
+		}
+	}
+
+	// the product categories of the report month only
+	monthIncidents := data.Incidents.FilterByMonthYear(data.Month, data.Year)
+	categories := monthIncidents.CollectProdCategories()
+	names := sla.SortProdCategoryNames(categories)
+	if len(names) > summaryTopProdCategories {
+		names = names[:summaryTopProdCategories]
+	}
+	fmt.Fprintf(w, "\n## Top %d product categories\n\n", len(names))
+	for index, name := range names {
+		category := categories[name]
+		fmt.Fprintf(w, "%d. %s: %d incidents, %d met SLA\n", index+1, name, category.Total, category.SLAMet)
+	}
+
+	if len(data.Warnings) > 0 {
+		fmt.Fprintf(w, "\n## Warnings\n\n")
+		for _, warning := range data.Warnings {
+			fmt.Fprintf(w, "- %s\n", warning)
+		}
+	}
+}
+
+// formatSummaryPercentage formats the SLA performance of a month with a marker if it is below target
+func formatSummaryPercentage(monthData sla.PriorityMonthData, target float64) string {
+	percentage, ok := monthData.Percentage()
+	if !ok {
+		return "-"
+	}
+	result := strconv.FormatFloat(percentage*100, 'f', 1, 64) + "%"
+	if percentage < target {
+		result = "**" + result + "**" + breachMarker
+	}
+	return result
+}
@@ -0,0 +1,498 @@
+// Package xlsx renders the report data of package sla to an Excel workbook.
+package xlsx
+
+import (
+	"fmt"
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"strconv"
+
+	"github.com/ronaldlens/goreport/sla"
+)
+
+// Sheet is a struct containing the filename and the excelize.File
+type Sheet struct {
+	filename string
+	file     *excelize.File
+}
+
+func (sheet *Sheet) init() {
+	sheet.file = excelize.NewFile()
+}
+
+func (sheet *Sheet) setupOverviewSheet(area string) {
+	xls := sheet.file
+	if area == "" {
+		xls.SetActiveSheet(xls.NewSheet("Overview"))
+	} else {
+		area = " " + area
+		xls.SetActiveSheet(xls.NewSheet("Overview" + area))
+	}
+
+	percentStyle, _ := xls.NewStyle(`{"number_format": 9}`)
+
+	_ = xls.SetCellStr("Overview"+area, "A2", "Total Incidents"+area)
+	_ = xls.SetCellStr("Overview"+area, "A3", "Priority")
+	_ = xls.SetCellStr("Overview"+area, "A9", "SLA Met Incidents"+area)
+	_ = xls.SetCellStr("Overview"+area, "A10", "Priority")
+	_ = xls.SetCellStr("Overview"+area, "A16", "SLA Performance"+area)
+	_ = xls.SetCellStr("Overview"+area, "A17", "Priority")
+	_ = xls.SetCellStr("Overview"+area, "B17", "Target")
+
+	for idx, priorityName := range sla.PriorityNames {
+		axis, _ := excelize.CoordinatesToCellName(1, idx+4)
+		_ = xls.SetCellStr("Overview"+area, axis, priorityName)
+		axis, _ = excelize.CoordinatesToCellName(1, idx+11)
+		_ = xls.SetCellStr("Overview"+area, axis, priorityName)
+		axis, _ = excelize.CoordinatesToCellName(1, idx+18)
+		_ = xls.SetCellStr("Overview"+area, axis, priorityName)
+
+		axis, _ = excelize.CoordinatesToCellName(2, idx+18)
+		_ = xls.SetCellFloat("Overview"+area, axis, sla.SLATarget, 2, 32)
+		_ = xls.SetCellStyle("Overview"+area, axis, axis, percentStyle)
+	}
+}
+
+// Render writes the report data to a new workbook
+func (sheet *Sheet) Render(data *sla.ReportData, filename string) error {
+	sheet.init()
+	for _, area := range data.Areas {
+		sheet.setupOverviewSheet(area.Name)
+		sheet.addOverviewToSheet(area, data.Months)
+		if err := sheet.createCharts(area.Name); err != nil {
+			return err
+		}
+	}
+	sheet.addProdCategoriesToSheet(data.ProdCategories)
+	sheet.addIncidentsToSheet(data.Incidents, "Incidents")
+	sheet.addAdjustmentsToSheet(data.Incidents, data.Warnings)
+	sheet.addBacklogToSheet(data.Backlog, data.Months)
+	sheet.addIncidentsToSheet(data.LocalIncidents, "Local Incidents")
+	return sheet.SaveAs(filename)
+}
+
+// addOverviewToSheet fills in the tables set up by setupOverviewSheet and the availability table
+func (sheet *Sheet) addOverviewToSheet(areaData sla.AreaData, months []sla.ReportMonth) {
+	xls := sheet.file
+	area := areaData.Name
+	if area != "" {
+		area = " " + area
+	}
+	//percentStyle, _ := xls.NewStyle(`{"number_format": 9}`)
+	percentStyle2, _ := xls.NewStyle(`{"number_format": 10}`)
+	greenStyle, _ := xls.NewStyle(`{"fill":{"type":"pattern","color":["#00FF00"],"pattern":1},"number_format": 9, "alignment":{"horizontal":"center"}}`)
+	redStyle, _ := xls.NewStyle(`{"fill":{"type":"pattern","color":["#FF0000"],"pattern":1},"number_format": 9,"alignment":{"horizontal":"center"},"font":{"color":"#FFFFFF"}}`)
+	greenStyle2, _ := xls.NewStyle(`{"fill":{"type":"pattern","color":["#00FF00"],"pattern":1},"number_format": 10, "alignment":{"horizontal":"center"}}`)
+	redStyle2, _ := xls.NewStyle(`{"fill":{"type":"pattern","color":["#FF0000"],"pattern":1},"number_format": 10,"alignment":{"horizontal":"center"},"font":{"color":"#FFFFFF"}}`)
+
+	for index, month := range months {
+
+		// add the month label
+		axis, _ := excelize.CoordinatesToCellName(3+index, 3)
+		_ = xls.SetCellStr("Overview"+area, axis, month.Name)
+		axis, _ = excelize.CoordinatesToCellName(3+index, 10)
+		_ = xls.SetCellStr("Overview"+area, axis, month.Name)
+		axis, _ = excelize.CoordinatesToCellName(3+index, 17)
+		_ = xls.SetCellStr("Overview"+area, axis, month.Name)
+
+		for priority, priorityData := range areaData.Priorities {
+			monthData := priorityData.Months[index]
+			axis, _ = excelize.CoordinatesToCellName(3+index, 4+priority)
+			_ = xls.SetCellInt("Overview"+area, axis, monthData.Total)
+			axis, _ = excelize.CoordinatesToCellName(3+index, 11+priority)
+			_ = xls.SetCellInt("Overview"+area, axis, monthData.SLAMet)
+
+			if percentage, ok := monthData.Percentage(); ok {
+				axis, _ = excelize.CoordinatesToCellName(3+index, 18+priority)
+				_ = xls.SetCellFloat("Overview"+area, axis, percentage, 3, 64)
+				if percentage < priorityData.Target {
+					_ = xls.SetCellStyle("Overview"+area, axis, axis, redStyle)
+				} else {
+					_ = xls.SetCellStyle("Overview"+area, axis, axis, greenStyle)
+				}
+			}
+		}
+	}
+
+	// Service availability
+	if len(areaData.Availability) == 0 {
+		return
+	}
+
+	// set up the table
+	axis, _ := excelize.CoordinatesToCellName(1, 23)
+	_ = xls.SetCellStr("Overview"+area, axis, "IT Service Availability")
+	axis, _ = excelize.CoordinatesToCellName(2, 24)
+	_ = xls.SetCellStr("Overview"+area, axis, "Target")
+	_ = xls.SetColWidth("Overview"+area, "A", "A", 16.22)
+
+	// loop through all services to set the name and the target percentage
+	for idx, service := range areaData.Availability {
+		axis, _ := excelize.CoordinatesToCellName(1, 25+idx)
+		_ = xls.SetCellStr("Overview"+area, axis, service.Service)
+		axis, _ = excelize.CoordinatesToCellName(2, 25+idx)
+		_ = xls.SetCellFloat("Overview"+area, axis, service.Target, 3, 64)
+		_ = xls.SetCellStyle("Overview"+area, axis, axis, percentStyle2)
+	}
+
+	// loop through the 6 months of the report
+	for idx, month := range months {
+		axis, _ := excelize.CoordinatesToCellName(3+idx, 24)
+		_ = xls.SetCellStr("Overview"+area, axis, month.Name)
+		for serviceIdx, service := range areaData.Availability {
+			value := service.Months[idx]
+			axis, _ := excelize.CoordinatesToCellName(3+idx, 25+serviceIdx)
+			_ = xls.SetCellFloat("Overview"+area, axis, value, 3, 64)
+			_ = xls.SetCellStyle("Overview"+area, axis, axis, percentStyle2)
+			if value < service.Target {
+				_ = xls.SetCellStyle("Overview"+area, axis, axis, redStyle2)
+			} else {
+				_ = xls.SetCellStyle("Overview"+area, axis, axis, greenStyle2)
+			}
+		}
+	}
+}
+
+func (sheet *Sheet) addProdCategoriesToSheet(categories []sla.ProdCategoryData) {
+	xls := sheet.file
+	xls.SetActiveSheet(xls.NewSheet("ProdCat"))
+
+	// set the headers
+	_ = xls.SetCellStr("ProdCat", "A1", "Product Category")
+	_ = xls.SetCellStr("ProdCat", "B1", "Total")
+	_ = xls.SetCellStr("ProdCat", "C1", "Met SLA")
+	_ = xls.SetCellStr("ProdCat", "D1", "Critical")
+	_ = xls.SetCellStr("ProdCat", "E1", "High")
+	_ = xls.SetCellStr("ProdCat", "F1", "Medium")
+	_ = xls.SetCellStr("ProdCat", "G1", "Low")
+
+	// initialize the row
+	// initialize max length to later set the width of the name column
+	row := 1
+	maxLen := 1
+
+	for _, category := range categories {
+		// find the maximum width
+		if len(category.Name) > maxLen {
+			maxLen = len(category.Name)
+		}
+
+		// increment the row and turn into string
+		row++
+		rowStr := strconv.Itoa(row)
+
+		_ = xls.SetCellStr("ProdCat", "A"+rowStr, category.Name)
+		_ = xls.SetCellInt("ProdCat", "B"+rowStr, category.Total)
+		_ = xls.SetCellInt("ProdCat", "C"+rowStr, category.SLAMet)
+		_ = xls.SetCellInt("ProdCat", "D"+rowStr, category.Critical)
+		_ = xls.SetCellInt("ProdCat", "E"+rowStr, category.High)
+		_ = xls.SetCellInt("ProdCat", "F"+rowStr, category.Medium)
+		_ = xls.SetCellInt("ProdCat", "G"+rowStr, category.Low)
+	}
+
+	// turn on autofilter and set the width to fit the name in column A
+	_ = xls.AutoFilter("ProdCat", "A1", "G"+strconv.Itoa(row), "")
+	_ = xls.SetColWidth("ProdCat", "A", "A", 0.9*float64(maxLen))
+}
+
+func (sheet *Sheet) addIncidentsToSheet(incidents []sla.Incident, sheetName string) {
+	xls := sheet.file
+	xls.SetActiveSheet(xls.NewSheet(sheetName))
+	urlStyle, _ := xls.NewStyle(`{"font":{"color":"#1265BE","underline":"single"}}`)
+
+	// setup the header row
+	_ = xls.SetCellStr(sheetName, "A1", "ID")
+	_ = xls.SetCellStr(sheetName, "B1", "Created")
+	_ = xls.SetCellStr(sheetName, "C1", "Solved")
+	_ = xls.SetCellStr(sheetName, "D1", "Time Open")
+	_ = xls.SetCellStr(sheetName, "E1", "Corrected Open")
+	_ = xls.SetCellStr(sheetName, "F1", "Exclude")
+	_ = xls.SetCellStr(sheetName, "G1", "Priority")
+	_ = xls.SetCellStr(sheetName, "H1", "Product Category Tier 1")
+	_ = xls.SetCellStr(sheetName, "I1", "Product Category Tier 2")
+	_ = xls.SetCellStr(sheetName, "J1", "Service")
+	_ = xls.SetCellStr(sheetName, "K1", "Service CI")
+	_ = xls.SetCellStr(sheetName, "L1", "Business Area")
+	_ = xls.SetCellStr(sheetName, "M1", "SLA Met")
+	_ = xls.SetCellStr(sheetName, "N1", "Description")
+	_ = xls.SetCellStr(sheetName, "O1", "Resolution")
+	_ = xls.SetCellStr(sheetName, "P1", "Reason")
+	_ = xls.SetCellStr(sheetName, "Q1", "Reviewer")
+	_ = xls.SetCellStr(sheetName, "R1", "Rule")
+
+	maxProdCat1Len := 1
+	maxProdCat2Len := 1
+	maxSvcLen := 1
+	maxCILen := 1
+	maxDescLen := 1
+	maxResLen := 1
+
+	for row, incident := range incidents {
+		rowStr := strconv.Itoa(row + 2)
+
+		_ = xls.SetCellValue(sheetName, "A"+rowStr, incident.ID)
+		_ = xls.SetCellHyperLink(sheetName, "A"+rowStr, sla.IncidentURL(incident.ID), "External")
+		_ = xls.SetCellStyle(sheetName, "A"+rowStr, "A"+rowStr, urlStyle)
+
+		_ = xls.SetCellValue(sheetName, "B"+rowStr, incident.CreatedAt)
+		if incident.SLAReady {
+			_ = xls.SetCellValue(sheetName, "C"+rowStr, incident.SolvedAt)
+		}
+		_ = xls.SetCellValue(sheetName, "D"+rowStr, incident.OpenTime)
+		_ = xls.SetCellValue(sheetName, "E"+rowStr, incident.CorrectedTime)
+		_ = xls.SetCellValue(sheetName, "F"+rowStr, incident.Exclude)
+		_ = xls.SetCellValue(sheetName, "G"+rowStr, sla.PriorityNames[incident.Priority])
+		_ = xls.SetCellValue(sheetName, "H"+rowStr, incident.ProdCategory1)
+		_ = xls.SetCellValue(sheetName, "I"+rowStr, incident.ProdCategory2)
+		_ = xls.SetCellValue(sheetName, "J"+rowStr, incident.Service)
+		_ = xls.SetCellValue(sheetName, "K"+rowStr, incident.ServiceCI)
+		_ = xls.SetCellValue(sheetName, "L"+rowStr, incident.BusinessArea)
+		_ = xls.SetCellValue(sheetName, "M"+rowStr, incident.SLAMet)
+		_ = xls.SetCellValue(sheetName, "N"+rowStr, incident.Description)
+		_ = xls.SetCellValue(sheetName, "O"+rowStr, incident.Resolution)
+		_ = xls.SetCellValue(sheetName, "P"+rowStr, incident.Reason)
+		_ = xls.SetCellValue(sheetName, "Q"+rowStr, incident.Reviewer)
+		_ = xls.SetCellValue(sheetName, "R"+rowStr, incident.Rule)
+
+		if len(incident.ProdCategory1) > maxProdCat1Len {
+			maxProdCat1Len = len(incident.ProdCategory1)
+		}
+		if len(incident.ProdCategory2) > maxProdCat2Len {
+			maxProdCat2Len = len(incident.ProdCategory2)
+		}
+		if len(incident.ServiceCI) > maxCILen {
+			maxCILen = len(incident.ServiceCI)
+		}
+		if len(incident.Service) > maxSvcLen {
+			maxSvcLen = len(incident.Service)
+		}
+		if len(incident.Description) > maxDescLen {
+			maxDescLen = len(incident.Description)
+		}
+		if len(incident.Resolution) > maxResLen {
+			maxResLen = len(incident.Resolution)
+		}
+	}
+
+	_ = xls.SetColWidth(sheetName, "A", "A", 16.0)
+	_ = xls.SetColWidth(sheetName, "B", "B", 16.0)
+	_ = xls.SetColWidth(sheetName, "C", "C", 16.0)
+	_ = xls.SetColWidth(sheetName, "H", "H", 0.9*float64(maxProdCat1Len))
+	_ = xls.SetColWidth(sheetName, "I", "I", 0.9*float64(maxProdCat2Len))
+	_ = xls.SetColWidth(sheetName, "J", "J", 0.9*float64(maxSvcLen))
+	_ = xls.SetColWidth(sheetName, "K", "K", 0.9*float64(maxCILen))
+	_ = xls.SetColWidth(sheetName, "N", "N", 0.9*float64(maxDescLen))
+	_ = xls.SetColWidth(sheetName, "O", "O", 0.9*float64(maxResLen))
+
+	_ = xls.SetColWidth(sheetName, "P", "P", 40.0)
+
+	rowStr := strconv.Itoa(len(incidents) + 1)
+	_ = xls.AutoFilter(sheetName, "A1", "R"+rowStr, "")
+}
+
+// addAdjustmentsToSheet lists the excluded and corrected incidents with their open time and
+// SLA outcome before and after the adjustment, and who decided why, followed by the warnings of the report
+func (sheet *Sheet) addAdjustmentsToSheet(incidents []sla.Incident, warnings []string) {
+	const sheetName = "Adjustments"
+	xls := sheet.file
+	xls.NewSheet(sheetName)
+	urlStyle, _ := xls.NewStyle(`{"font":{"color":"#1265BE","underline":"single"}}`)
+
+	headers := []string{"ID", "Priority", "Created", "Open Before", "Open After", "Excluded",
+		"SLA Met Before", "SLA Met After", "Reason", "Reviewer"}
+	for index, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(index+1, 1)
+		_ = xls.SetCellStr(sheetName, cell, header)
+	}
+
+	row := 1
+	for _, incident := range incidents {
+		if !incident.Exclude && incident.CorrectedTime == "" {
+			continue
+		}
+		row++
+		rowStr := strconv.Itoa(row)
+
+		openAfter := incident.OpenTime
+		if incident.CorrectedTime != "" {
+			openAfter = int(incident.CorrectedOpenTime.Minutes())
+		}
+		slaMetAfter := formatSLAMet(incident.SLAMet)
+		if incident.Exclude {
+			slaMetAfter = "Excluded"
+		}
+
+		_ = xls.SetCellValue(sheetName, "A"+rowStr, incident.ID)
+		_ = xls.SetCellHyperLink(sheetName, "A"+rowStr, sla.IncidentURL(incident.ID), "External")
+		_ = xls.SetCellStyle(sheetName, "A"+rowStr, "A"+rowStr, urlStyle)
+		_ = xls.SetCellValue(sheetName, "B"+rowStr, sla.PriorityNames[incident.Priority])
+		_ = xls.SetCellValue(sheetName, "C"+rowStr, incident.CreatedAt)
+		_ = xls.SetCellValue(sheetName, "D"+rowStr, incident.OpenTime)
+		_ = xls.SetCellValue(sheetName, "E"+rowStr, openAfter)
+		_ = xls.SetCellValue(sheetName, "F"+rowStr, incident.Exclude)
+		_ = xls.SetCellValue(sheetName, "G"+rowStr, formatSLAMet(incident.UncorrectedSLAMet))
+		_ = xls.SetCellValue(sheetName, "H"+rowStr, slaMetAfter)
+		_ = xls.SetCellValue(sheetName, "I"+rowStr, incident.Reason)
+		_ = xls.SetCellValue(sheetName, "J"+rowStr, incident.Reviewer)
+	}
+
+	_ = xls.SetColWidth(sheetName, "A", "A", 16.0)
+	_ = xls.SetColWidth(sheetName, "C", "C", 16.0)
+	_ = xls.SetColWidth(sheetName, "D", "H", 14.0)
+	_ = xls.SetColWidth(sheetName, "I", "I", 40.0)
+	_ = xls.SetColWidth(sheetName, "J", "J", 16.0)
+	_ = xls.AutoFilter(sheetName, "A1", "J"+strconv.Itoa(row), "")
+
+	// below the table, so they are not hidden by the filter
+	if len(warnings) > 0 {
+		row += 2
+		_ = xls.SetCellStr(sheetName, "A"+strconv.Itoa(row), "Warnings")
+		for _, warning := range warnings {
+			row++
+			_ = xls.SetCellStr(sheetName, "A"+strconv.Itoa(row), warning)
+		}
+	}
+}
+
+// addBacklogToSheet adds the open incidents per priority and age, the backlog trend and the open incidents,
+// with the ones past their SLA due time in red
+func (sheet *Sheet) addBacklogToSheet(backlog sla.BacklogData, months []sla.ReportMonth) {
+	const sheetName = "Backlog"
+	xls := sheet.file
+	xls.NewSheet(sheetName)
+	urlStyle, _ := xls.NewStyle(`{"font":{"color":"#1265BE","underline":"single"}}`)
+	redStyle, _ := xls.NewStyle(`{"fill":{"type":"pattern","color":["#FF0000"],"pattern":1},"font":{"color":"#FFFFFF"}}`)
+
+	_ = xls.SetCellStr(sheetName, "A1", "Open incidents at "+backlog.At.Format("2006-01-02 15:04"))
+
+	// the ages per priority, with the total and overdue columns after the age buckets
+	headers := append(append([]string{"Priority"}, backlog.Ages...), "Total", "Overdue")
+	for index, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(index+1, 3)
+		_ = xls.SetCellStr(sheetName, cell, header)
+	}
+	total := sla.BacklogPriorityData{Priority: "Total", Ages: make([]int, len(backlog.Ages))}
+	writeAges := func(row int, priorityData sla.BacklogPriorityData) {
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		_ = xls.SetCellStr(sheetName, cell, priorityData.Priority)
+		values := append(append([]int{}, priorityData.Ages...), priorityData.Total, priorityData.Overdue)
+		for index, value := range values {
+			cell, _ = excelize.CoordinatesToCellName(index+2, row)
+			_ = xls.SetCellInt(sheetName, cell, value)
+		}
+		if priorityData.Overdue > 0 {
+			_ = xls.SetCellStyle(sheetName, cell, cell, redStyle)
+		}
+	}
+	for index, priorityData := range backlog.Priorities {
+		writeAges(index+4, priorityData)
+		for bucket, count := range priorityData.Ages {
+			total.Ages[bucket] += count
+		}
+		total.Total += priorityData.Total
+		total.Overdue += priorityData.Overdue
+	}
+	writeAges(len(backlog.Priorities)+4, total)
+
+	// the number of open incidents at the end of each month
+	trendRow := len(backlog.Priorities) + 7
+	_ = xls.SetCellStr(sheetName, "A"+strconv.Itoa(trendRow), "Month")
+	_ = xls.SetCellStr(sheetName, "A"+strconv.Itoa(trendRow+1), "Open")
+	for index, open := range backlog.Trend {
+		cell, _ := excelize.CoordinatesToCellName(index+2, trendRow)
+		_ = xls.SetCellStr(sheetName, cell, months[index].Name)
+		cell, _ = excelize.CoordinatesToCellName(index+2, trendRow+1)
+		_ = xls.SetCellInt(sheetName, cell, open)
+	}
+
+	listRow := trendRow + 3
+	headers = []string{"ID", "Priority", "Created", "Due", "Age (days)", "Age", "Overdue", "Service", "Description"}
+	for index, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(index+1, listRow)
+		_ = xls.SetCellStr(sheetName, cell, header)
+	}
+	for index, incident := range backlog.Incidents {
+		rowStr := strconv.Itoa(listRow + index + 1)
+
+		_ = xls.SetCellValue(sheetName, "A"+rowStr, incident.ID)
+		_ = xls.SetCellHyperLink(sheetName, "A"+rowStr, sla.IncidentURL(incident.ID), "External")
+		_ = xls.SetCellStyle(sheetName, "A"+rowStr, "A"+rowStr, urlStyle)
+		_ = xls.SetCellValue(sheetName, "B"+rowStr, sla.PriorityNames[incident.Priority])
+		_ = xls.SetCellValue(sheetName, "C"+rowStr, incident.CreatedAt)
+		_ = xls.SetCellValue(sheetName, "D"+rowStr, incident.Due)
+		_ = xls.SetCellFloat(sheetName, "E"+rowStr, incident.Age.Hours()/24, 1, 64)
+		_ = xls.SetCellValue(sheetName, "F"+rowStr, incident.Bucket)
+		_ = xls.SetCellValue(sheetName, "G"+rowStr, formatSLAMet(incident.Overdue))
+		_ = xls.SetCellValue(sheetName, "H"+rowStr, incident.Service)
+		_ = xls.SetCellValue(sheetName, "I"+rowStr, incident.Description)
+		if incident.Overdue {
+			_ = xls.SetCellStyle(sheetName, "D"+rowStr, "D"+rowStr, redStyle)
+			_ = xls.SetCellStyle(sheetName, "G"+rowStr, "G"+rowStr, redStyle)
+		}
+	}
+
+	_ = xls.SetColWidth(sheetName, "A", "A", 16.0)
+	_ = xls.SetColWidth(sheetName, "C", "D", 16.0)
+	_ = xls.SetColWidth(sheetName, "H", "H", 24.0)
+	_ = xls.SetColWidth(sheetName, "I", "I", 60.0)
+	_ = xls.AutoFilter(sheetName, "A"+strconv.Itoa(listRow), "I"+strconv.Itoa(listRow+len(backlog.Incidents)), "")
+}
+
+func formatSLAMet(slaMet bool) string {
+	if slaMet {
+		return "Yes"
+	}
+	return "No"
+}
+
+// createCharts adds the total incidents and SLA performance charts to the overview of an area
+func (sheet *Sheet) createCharts(area string) error {
+	xls := sheet.file
+	if area != "" {
+		area = " " + area
+	}
+
+	series := ""
+	for _, i := range []int{4, 5, 6, 7} {
+		series += fmt.Sprintf("{\"name\":\"'Overview"+area+"'!$A$%d\",\"categories\":\"'Overview"+area+"'!$C$3:$H$3\",\"values\":\"'Overview"+area+"'!$C%d:$H%d\"}", i, i, i)
+		if i != 7 {
+			series += ","
+		}
+	}
+	cs := fmt.Sprintf("{\"type\":\"line\",\"series\":[%s],", series)
+	cs += "\"format\":{\"x_scale\":1.0,\"y_scale\":1.0,\"x_offset\":15,\"y_offset\":10,\"print_obj\":true,\"lock_aspect_ratio\":false,\"locked\":false},"
+	cs += "\"legend\":{\"position\":\"bottom\",\"show_legend_key\":false},"
+	cs += "\"title\":{\"name\":\"Total Incidents" + area + "\"},"
+	cs += "\"plotarea\":{\"show_bubble_size\":false,\"show_cat_name\":false,\"show_leader_lines\":true,\"show_percent\":false,\"show_series_name\":false,\"show_val\":false},\"show_blanks_as\":\"gap\"}"
+
+	err := xls.AddChart("Overview"+area, "J2", cs)
+	if err != nil {
+		return fmt.Errorf("adding total incidents chart: %w", err)
+	}
+
+	series = ""
+	for _, i := range []int{18, 19, 20, 21} {
+		series += fmt.Sprintf("{\"name\":\"'Overview"+area+"'!$A$%d\",\"categories\":\"'Overview"+area+"'!$C$3:$H$3\",\"values\":\"'Overview"+area+"'!$C%d:$H%d\"}", i, i, i)
+		if i != 21 {
+			series += ","
+		}
+	}
+	cs = fmt.Sprintf("{\"type\":\"line\",\"series\":[%s],", series)
+	cs += "\"format\":{\"x_scale\":1.0,\"y_scale\":1.0,\"x_offset\":15,\"y_offset\":10,\"print_obj\":true,\"lock_aspect_ratio\":false,\"locked\":false},"
+	cs += "\"legend\":{\"position\":\"bottom\",\"show_legend_key\":false},"
+	cs += "\"title\":{\"name\":\"SLA Performance" + area + "\"},"
+	cs += "\"plotarea\":{\"show_bubble_size\":false,\"show_cat_name\":false,\"show_leader_lines\":true,\"show_percent\":false,\"show_series_name\":false,\"show_val\":false},\"show_blanks_as\":\"gap\"}"
+
+	err = xls.AddChart("Overview"+area, "J18", cs)
+	if err != nil {
+		return fmt.Errorf("adding SLA performance chart: %w", err)
+	}
+	return nil
+}
+
+// SaveAs fixes the sheets (removes the default 'Sheet1' and sets the active sheet to the next one)
+// and saves it. It returns an error if saving fails
+func (sheet *Sheet) SaveAs(filename string) error {
+	sheet.file.DeleteSheet("Sheet1")
+	sheet.file.SetActiveSheet(2)
+	return sheet.file.SaveAs(filename)
+}